@@ -0,0 +1,187 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// BatchCache is a [Cache] that can read and write several keys in one
+// round trip. [MFetch] uses it when available and otherwise falls back to
+// one call per key.
+type BatchCache interface {
+	Cache
+	// MGet returns the values of the keys that are present. Missing keys
+	// are absent from the result.
+	MGet(ctx context.Context, keys []string) (map[string][]byte, error)
+	// MSet stores every item with the same ttl.
+	MSet(ctx context.Context, items map[string][]byte, ttl time.Duration) error
+}
+
+// MFetch is the batch form of [Fetch]. It looks up the cache key of every
+// id, calls fn once with the ids that were missing, stores what fn returned
+// and returns the results keyed by id.
+//
+// keyFn maps an id to its cache key. fn receives the missing ids in the
+// order they appear in ids, without duplicates, and may omit ids it cannot
+// find; those are absent from the result as well. If fn fails, MFetch
+// returns its error and no results.
+//
+// A tombstone left by [Fetch] with [WithNegativeCache] is never decoded as a
+// value: if its class is registered in options the id is treated as not
+// found, absent from the result and not passed to fn; otherwise it is a
+// miss.
+//
+// If c is nil, fn is called with all ids. Of the [FetchOption]s, only
+// [WithExpiration], [WithMarshalFunc], [WithUnmarshalFunc],
+// [WithNegativeCache], [WithErrorPolicy], [WithCorruptPolicy],
+// [WithSetErrorCallback] and [WithGetErrorCallback] apply; errors are
+// reported per key.
+func MFetch[K comparable, T any](ctx context.Context, c Cache, ids []K, keyFn func(K) string, fn func(ids []K) (map[K]T, error), options ...FetchOption) (map[K]T, error) {
+	ids = dedup(ids)
+	if c == nil {
+		return fn(ids)
+	}
+	if len(ids) == 0 {
+		return map[K]T{}, nil
+	}
+
+	opts := newFetchOptions(options)
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = keyFn(id)
+	}
+	hits, err := mget(ctx, c, keys, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[K]T, len(ids))
+	var missing []K
+	for i, id := range ids {
+		data, ok := hits[keys[i]]
+		if ok {
+			if err, tomb := decodeTombstone(opts.negative, data); tomb {
+				if err == nil {
+					missing = append(missing, id)
+				}
+				continue
+			}
+			if entry, stale := decodeStale(data); stale {
+				data = entry.data
+			}
+			var v T
+			uerr := opts.unmarshalFn(data, &v)
+			if uerr == nil {
+				results[id] = v
+				continue
+			}
+			if err := corrupt(ctx, c, keys[i], uerr, &opts); err != nil {
+				return nil, err
+			}
+		}
+		missing = append(missing, id)
+	}
+	if len(missing) == 0 {
+		return results, nil
+	}
+
+	loaded, err := fn(missing)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make(map[string][]byte, len(loaded))
+	for _, id := range missing {
+		v, ok := loaded[id]
+		if !ok {
+			continue
+		}
+		results[id] = v
+		key := keyFn(id)
+		data, err := opts.marshalFn(v)
+		if err != nil {
+			if opts.onSetError != nil {
+				opts.onSetError(key, errors.WithStack(err))
+			}
+			continue
+		}
+		items[key] = data
+	}
+	mset(ctx, c, items, opts.expiration, opts.onSetError)
+
+	return results, nil
+}
+
+// dedup returns ids without duplicates, keeping the first occurrence.
+func dedup[K comparable](ids []K) []K {
+	seen := make(map[K]struct{}, len(ids))
+	out := make([]K, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
+
+// mget reads keys with [BatchCache.MGet] if c supports it, and otherwise
+// with one Get per key. Read errors are handled like in [Fetch]: the
+// returned error is non-nil only when the [ErrorPolicy] is [FailClosed].
+func mget(ctx context.Context, c Cache, keys []string, opts *fetchOptions) (map[string][]byte, error) {
+	if bc, ok := c.(BatchCache); ok {
+		hits, err := bc.MGet(ctx, keys)
+		if err == nil {
+			return hits, nil
+		}
+		err = errors.WithStack(err)
+		for _, key := range keys {
+			opts.reportGetError(key, err)
+		}
+		if opts.errorPolicy == FailClosed {
+			return nil, err
+		}
+		return nil, nil
+	}
+	hits := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		data, hit, err := read(ctx, c, key, opts)
+		if err != nil {
+			return nil, err
+		}
+		if hit {
+			hits[key] = data
+		}
+	}
+	return hits, nil
+}
+
+// mset writes items with [BatchCache.MSet] if c supports it, and otherwise
+// with one Set per item. Failed keys are reported to onErr, if not nil; a
+// failed MSet reports every key.
+func mset(ctx context.Context, c Cache, items map[string][]byte, ttl time.Duration, onErr func(key string, err error)) {
+	if len(items) == 0 {
+		return
+	}
+	if onErr == nil {
+		onErr = func(string, error) {}
+	}
+	if bc, ok := c.(BatchCache); ok {
+		if err := bc.MSet(ctx, items, ttl); err != nil {
+			err = errors.WithStack(err)
+			for key := range items {
+				onErr(key, err)
+			}
+		}
+		return
+	}
+	for key, data := range items {
+		if err := c.Set(ctx, key, data, ttl); err != nil {
+			onErr(key, errors.WithStack(err))
+		}
+	}
+}
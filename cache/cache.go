@@ -4,7 +4,18 @@
 // Fetch first looks up the key in the cache. On a hit it deserializes and
 // returns the cached value; on a miss it calls the provided function, caches
 // the result, and returns it. Serialization, expiration, and error handling
-// are configurable through [FetchOption] functions.
+// are configurable through [FetchOption] functions, as is deduplication of
+// concurrent misses through a shared [Group]. [MFetch] does the same for a
+// batch of keys, using [BatchCache] when the cache supports it.
+//
+// Values are encoded with a [Codec], optionally inside a versioned,
+// compressed envelope (see [NewEnvelope]).
+//
+// [Namespace] builds versioned keys that can be invalidated as a group, and
+// [Tagged] adds tag-based invalidation on top of any Cache.
+//
+// [Tiered] composes two caches into a local tier in front of a shared remote
+// one, kept coherent across instances through an [InvalidationBus].
 package cache
 
 import (
@@ -15,7 +26,8 @@ import (
 	"github.com/pkg/errors"
 )
 
-// Cache is a minimal byte-level key-value cache abstraction.
+// Cache is a minimal byte-level key-value cache abstraction. Get reports a
+// missing key with [ErrCacheMiss].
 type Cache interface {
 	Set(ctx context.Context, key string, val []byte, ttl time.Duration) error
 	Get(ctx context.Context, key string) ([]byte, error)
@@ -28,6 +40,19 @@ type fetchOptions struct {
 	marshalFn   func(interface{}) ([]byte, error)
 	unmarshalFn func([]byte, interface{}) error
 	onSetError  func(key string, err error)
+	group       *Group
+	flightTTL   time.Duration
+	negative    []negativeClass
+
+	softTTL        time.Duration
+	beta           float64
+	onRefreshError func(key string, err error)
+
+	errorPolicy   ErrorPolicy
+	corruptPolicy CorruptPolicy
+	onGetError    func(key string, err error)
+
+	tags []string
 }
 
 // FetchOption configures the behavior of [Fetch].
@@ -65,6 +90,95 @@ func WithSetErrorCallback(fn func(key string, err error)) FetchOption {
 	}
 }
 
+// WithSingleflight collapses concurrent misses for the same key into a
+// single call of fn whose result is shared by every caller waiting on g.
+// The shared load, including the cache write, is not cancelled when the
+// caller that started it gives up; each caller only stops waiting.
+func WithSingleflight(g *Group) FetchOption {
+	return func(opts *fetchOptions) {
+		opts.group = g
+	}
+}
+
+// WithFlightTimeout bounds how long a caller waits for a shared load started
+// through [WithSingleflight]. A caller that times out gets
+// [ErrFlightTimeout], and the next miss starts a fresh load. The default is
+// 0, meaning no timeout.
+func WithFlightTimeout(d time.Duration) FetchOption {
+	return func(opts *fetchOptions) {
+		opts.flightTTL = d
+	}
+}
+
+// WithNegativeCache caches failures of fn that belong to the error class
+// err, such as a record-not-found error, so that lookups of missing keys do
+// not reach fn every time. match decides whether an error returned by fn
+// belongs to the class; a nil match uses [errors.Is] against err. Matching
+// failures are stored as a tombstone with the given ttl, which is usually
+// shorter than the one set by [WithExpiration], and later hits return err.
+//
+// The option may be given several times to cache several classes. Classes
+// are told apart by their messages, so each must have a distinct one.
+//
+//	cache.Fetch(ctx, c, key, load,
+//		cache.WithNegativeCache(gorm.ErrRecordNotFound, nil, time.Minute))
+func WithNegativeCache(err error, match func(error) bool, ttl time.Duration) FetchOption {
+	if match == nil {
+		match = func(e error) bool {
+			return errors.Is(e, err)
+		}
+	}
+	return func(opts *fetchOptions) {
+		opts.negative = append(opts.negative, negativeClass{err: err, match: match, ttl: ttl})
+	}
+}
+
+// WithStaleWhileRevalidate stores a soft expiry of d alongside the value.
+// Once it has passed, Fetch keeps returning the stale value and refreshes it
+// in the background, so callers do not wait for fn while popular entries
+// are reloaded. The entry only becomes a miss once the hard TTL set with
+// [WithExpiration] runs out, so d should be shorter than that.
+func WithStaleWhileRevalidate(d time.Duration) FetchOption {
+	return func(opts *fetchOptions) {
+		opts.softTTL = d
+	}
+}
+
+// WithEarlyRefresh enables probabilistic early refresh (XFetch): each hit
+// may start a background refresh before the soft expiry, with a probability
+// that rises as the expiry approaches and with the time fn took to compute
+// the value. beta scales how early refreshes happen; 1 is a good default
+// and larger values refresh earlier. Without [WithStaleWhileRevalidate] the
+// hard TTL is used as the expiry.
+func WithEarlyRefresh(beta float64) FetchOption {
+	return func(opts *fetchOptions) {
+		opts.beta = beta
+	}
+}
+
+// WithRefreshErrorCallback registers a callback that is invoked when a
+// background refresh started by [WithStaleWhileRevalidate] or
+// [WithEarlyRefresh] fails. The stale value stays in place until its hard
+// TTL expires. By default such failures are silently ignored.
+func WithRefreshErrorCallback(fn func(key string, err error)) FetchOption {
+	return func(opts *fetchOptions) {
+		opts.onRefreshError = fn
+	}
+}
+
+// newFetchOptions applies options over the defaults.
+func newFetchOptions(options []FetchOption) fetchOptions {
+	opts := fetchOptions{
+		expiration:  5 * time.Minute,
+		marshalFn:   json.Marshal,
+		unmarshalFn: json.Unmarshal,
+	}
+	for _, option := range options {
+		option(&opts)
+	}
+	return opts
+}
+
 // Fetch implements the cache-aside pattern for an arbitrary value type T.
 //
 // It first attempts to read key from c. On a cache hit the value is
@@ -73,31 +187,72 @@ func WithSetErrorCallback(fn func(key string, err error)) FetchOption {
 //
 // If c is nil, fn is called directly (no caching). Cache-write errors do
 // not affect the return value; they are reported via [WithSetErrorCallback]
-// if configured.
+// if configured. With [WithSingleflight], concurrent misses for key share
+// a single call of fn; with [WithNegativeCache], selected failures of fn are
+// cached as well. [WithStaleWhileRevalidate] and [WithEarlyRefresh] serve
+// cached values while refreshing them in the background.
+//
+// A read error other than [ErrCacheMiss] is handled according to
+// [WithErrorPolicy], and an entry that cannot be decoded according to
+// [WithCorruptPolicy]; both are reported via [WithGetErrorCallback].
 func Fetch[T any](ctx context.Context, c Cache, key string, fn func() (T, error), options ...FetchOption) (T, error) {
 	if c == nil {
 		return fn()
 	}
 
-	opts := fetchOptions{
-		expiration:  5 * time.Minute,
-		marshalFn:   json.Marshal,
-		unmarshalFn: json.Unmarshal,
+	opts := newFetchOptions(options)
+
+	data, hit, err := read(ctx, c, key, &opts)
+	if err != nil {
+		var zero T
+		return zero, err
 	}
-	for _, option := range options {
-		option(&opts)
+	if hit {
+		if err, ok := decodeTombstone(opts.negative, data); ok {
+			if err != nil {
+				var zero T
+				return zero, errors.WithStack(err)
+			}
+		} else {
+			entry, stale := decodeStale(data)
+			if stale {
+				data = entry.data
+			}
+			var v T
+			uerr := opts.unmarshalFn(data, &v)
+			if uerr == nil {
+				if stale && entry.needsRefresh(time.Now(), opts.beta) {
+					refresh(ctx, c, key, fn, &opts)
+				}
+				return v, nil
+			}
+			if err := corrupt(ctx, c, key, uerr, &opts); err != nil {
+				var zero T
+				return zero, err
+			}
+		}
 	}
 
-	data, err := c.Get(ctx, key)
-	if err == nil {
-		var v T
-		if err := opts.unmarshalFn(data, &v); err == nil {
-			return v, nil
-		}
+	if opts.group == nil {
+		return load(ctx, c, key, fn, &opts)
 	}
+	v, err := opts.group.do(ctx, key, opts.flightTTL, func(ctx context.Context) (interface{}, error) {
+		return load(ctx, c, key, fn, &opts)
+	})
+	result, _ := v.(T)
+	return result, err
+}
 
+// load calls fn and stores its result in c.
+func load[T any](ctx context.Context, c Cache, key string, fn func() (T, error), opts *fetchOptions) (T, error) {
+	start := time.Now()
 	result, err := fn()
 	if err != nil {
+		if class, ok := matchNegative(opts.negative, err); ok {
+			if serr := c.Set(ctx, key, encodeTombstone(class), class.ttl); serr != nil && opts.onSetError != nil {
+				opts.onSetError(key, errors.WithStack(serr))
+			}
+		}
 		return result, err
 	}
 
@@ -106,6 +261,17 @@ func Fetch[T any](ctx context.Context, c Cache, key string, fn func() (T, error)
 		if err != nil {
 			return errors.WithStack(err)
 		}
+		if opts.softTTL > 0 || opts.beta > 0 {
+			softTTL := opts.softTTL
+			if softTTL <= 0 {
+				softTTL = opts.expiration
+			}
+			now := time.Now()
+			data = encodeStale(data, now.Add(softTTL), now.Sub(start))
+		}
+		if ts, ok := c.(TagSetter); ok && len(opts.tags) > 0 {
+			return errors.WithStack(ts.SetWithTags(ctx, key, data, opts.expiration, opts.tags...))
+		}
 		if err := c.Set(ctx, key, data, opts.expiration); err != nil {
 			return errors.WithStack(err)
 		}
@@ -0,0 +1,438 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+var errNotFound = errors.New("not found")
+
+// mapCache is a minimal [Cache] used by the tests.
+type mapCache struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func newMapCache() *mapCache {
+	return &mapCache{data: make(map[string][]byte)}
+}
+
+func (c *mapCache) Set(_ context.Context, key string, val []byte, _ time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[key] = val
+	return nil
+}
+
+func (c *mapCache) Get(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	val, ok := c.data[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	return val, nil
+}
+
+func (c *mapCache) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.data, key)
+	return nil
+}
+
+func TestFetch(t *testing.T) {
+	c := newMapCache()
+	var calls int
+	fn := func() (int, error) {
+		calls++
+		return 42, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		val, err := Fetch(context.Background(), c, "k", fn)
+		require.NoError(t, err)
+		assert.Equal(t, 42, val)
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestFetch_Singleflight(t *testing.T) {
+	c := newMapCache()
+	var g Group
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fn := func() (int, error) {
+		calls.Add(1)
+		<-release
+		return 7, nil
+	}
+
+	const n = 10
+	var wg sync.WaitGroup
+	wg.Add(n)
+	results := make([]int, n)
+	for i := 0; i < n; i++ {
+		i := i
+		go func() {
+			defer wg.Done()
+			val, err := Fetch(context.Background(), c, "k", fn, WithSingleflight(&g))
+			assert.NoError(t, err)
+			results[i] = val
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, val := range results {
+		assert.Equal(t, 7, val)
+	}
+}
+
+func TestFetch_SingleflightLeaderCancelled(t *testing.T) {
+	c := newMapCache()
+	var g Group
+	release := make(chan struct{})
+	fn := func() (int, error) {
+		<-release
+		return 7, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error, 1)
+	go func() {
+		_, err := Fetch(ctx, c, "k", fn, WithSingleflight(&g))
+		leader <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	follower := make(chan int, 1)
+	go func() {
+		val, _ := Fetch(context.Background(), c, "k", fn, WithSingleflight(&g))
+		follower <- val
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-leader, context.Canceled)
+	close(release)
+	assert.Equal(t, 7, <-follower)
+
+	// The shared load still populated the cache.
+	val, err := Fetch(context.Background(), c, "k", func() (int, error) { return 0, errNotFound })
+	require.NoError(t, err)
+	assert.Equal(t, 7, val)
+}
+
+func TestFetch_FlightTimeout(t *testing.T) {
+	c := newMapCache()
+	var g Group
+	release := make(chan struct{})
+	defer close(release)
+
+	_, err := Fetch(context.Background(), c, "k", func() (int, error) {
+		<-release
+		return 1, nil
+	}, WithSingleflight(&g), WithFlightTimeout(10*time.Millisecond))
+	assert.ErrorIs(t, err, ErrFlightTimeout)
+
+	// The stuck load was forgotten, so the next miss starts a new one.
+	val, err := Fetch(context.Background(), c, "k", func() (int, error) {
+		return 2, nil
+	}, WithSingleflight(&g))
+	require.NoError(t, err)
+	assert.Equal(t, 2, val)
+}
+
+func TestFetch_EmptyValue(t *testing.T) {
+	ctx := context.Background()
+	c := newMapCache()
+	calls := 0
+	for i := 0; i < 3; i++ {
+		// BinaryCodec stores an empty []byte as nil data.
+		val, err := Fetch(ctx, c, "k", func() ([]byte, error) {
+			calls++
+			return nil, nil
+		}, WithCodec(BinaryCodec))
+		require.NoError(t, err)
+		assert.Empty(t, val)
+	}
+	assert.Equal(t, 1, calls)
+
+	res, err := MFetch(ctx, c, []string{"k"}, func(id string) string { return id },
+		func(ids []string) (map[string][]byte, error) {
+			calls++
+			return nil, nil
+		}, WithCodec(BinaryCodec))
+	require.NoError(t, err)
+	assert.Contains(t, res, "k")
+	assert.Equal(t, 1, calls)
+}
+
+func TestFetch_NegativeCache(t *testing.T) {
+	c := newMapCache()
+	var calls int
+	fn := func() (int, error) {
+		calls++
+		return 0, errors.Wrap(errNotFound, "load user")
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := Fetch(context.Background(), c, "k", fn, WithNegativeCache(errNotFound, nil, time.Minute))
+		assert.ErrorIs(t, err, errNotFound)
+	}
+	assert.Equal(t, 1, calls)
+
+	// Without the option the tombstone is treated as a miss.
+	val, err := Fetch(context.Background(), c, "k", func() (int, error) { return 1, nil })
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+
+	// Errors outside the class are not cached.
+	other := errors.New("boom")
+	for i := 0; i < 2; i++ {
+		_, err := Fetch(context.Background(), c, "other", func() (int, error) {
+			calls++
+			return 0, other
+		}, WithNegativeCache(errNotFound, nil, time.Minute))
+		assert.ErrorIs(t, err, other)
+	}
+	assert.Equal(t, 3, calls)
+}
+
+func TestFetch_StaleWhileRevalidate(t *testing.T) {
+	c := newMapCache()
+	var calls atomic.Int32
+	fn := func() (int, error) {
+		return int(calls.Add(1)), nil
+	}
+	opts := []FetchOption{WithStaleWhileRevalidate(10 * time.Millisecond)}
+
+	val, err := Fetch(context.Background(), c, "k", fn, opts...)
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+
+	time.Sleep(20 * time.Millisecond)
+	// Past the soft expiry the stale value is served while it is refreshed.
+	val, err = Fetch(context.Background(), c, "k", fn, opts...)
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+
+	assert.Eventually(t, func() bool {
+		val, err := Fetch(context.Background(), c, "k", fn, opts...)
+		return err == nil && val == 2
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestFetch_EarlyRefresh(t *testing.T) {
+	c := newMapCache()
+	var calls atomic.Int32
+	fn := func() (int, error) {
+		time.Sleep(time.Millisecond)
+		return int(calls.Add(1)), nil
+	}
+	// A huge beta makes every hit refresh early, long before the expiry.
+	opts := []FetchOption{WithStaleWhileRevalidate(time.Hour), WithEarlyRefresh(1e9)}
+
+	val, err := Fetch(context.Background(), c, "k", fn, opts...)
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+
+	assert.Eventually(t, func() bool {
+		val, err := Fetch(context.Background(), c, "k", fn, opts...)
+		return err == nil && val > 1
+	}, time.Second, 5*time.Millisecond)
+
+	// With early refresh disabled, a fresh entry is never reloaded.
+	var loads int
+	load := func() (int, error) {
+		loads++
+		return loads, nil
+	}
+	for i := 0; i < 2; i++ {
+		_, err = Fetch(context.Background(), c, "k2", load, WithStaleWhileRevalidate(time.Hour))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, loads)
+}
+
+// batchCache adds [BatchCache] support to mapCache and counts round trips.
+type batchCache struct {
+	*mapCache
+	mgets, msets int
+}
+
+func (c *batchCache) MGet(ctx context.Context, keys []string) (map[string][]byte, error) {
+	c.mgets++
+	hits := make(map[string][]byte)
+	for _, key := range keys {
+		if val, err := c.Get(ctx, key); err == nil {
+			hits[key] = val
+		}
+	}
+	return hits, nil
+}
+
+func (c *batchCache) MSet(ctx context.Context, items map[string][]byte, ttl time.Duration) error {
+	c.msets++
+	for key, val := range items {
+		if err := c.Set(ctx, key, val, ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestMFetch(t *testing.T) {
+	keyFn := func(id int) string { return fmt.Sprintf("user:%d", id) }
+
+	for name, c := range map[string]Cache{
+		"batch":    &batchCache{mapCache: newMapCache()},
+		"fallback": newMapCache(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var loaded [][]int
+			fn := func(ids []int) (map[int]string, error) {
+				loaded = append(loaded, ids)
+				res := make(map[int]string)
+				for _, id := range ids {
+					if id != 404 {
+						res[id] = fmt.Sprint("u", id)
+					}
+				}
+				return res, nil
+			}
+
+			res, err := MFetch(context.Background(), c, []int{3, 1, 3}, keyFn, fn)
+			require.NoError(t, err)
+			assert.Equal(t, map[int]string{1: "u1", 3: "u3"}, res)
+
+			res, err = MFetch(context.Background(), c, []int{1, 2, 404, 3}, keyFn, fn)
+			require.NoError(t, err)
+			assert.Equal(t, map[int]string{1: "u1", 2: "u2", 3: "u3"}, res)
+			assert.Equal(t, [][]int{{3, 1}, {2, 404}}, loaded)
+			if bc, ok := c.(*batchCache); ok {
+				assert.Equal(t, 2, bc.mgets)
+				assert.Equal(t, 2, bc.msets)
+			}
+		})
+	}
+}
+
+func TestMFetch_Tombstones(t *testing.T) {
+	ctx := context.Background()
+	c := newMapCache()
+	keyFn := func(id int) string { return fmt.Sprintf("user:%d", id) }
+	negative := WithNegativeCache(errNotFound, nil, time.Minute)
+
+	_, err := Fetch(ctx, c, keyFn(404), func() (string, error) {
+		return "", errNotFound
+	}, negative)
+	require.ErrorIs(t, err, errNotFound)
+
+	var loaded [][]int
+	fn := func(ids []int) (map[int]string, error) {
+		loaded = append(loaded, ids)
+		res := make(map[int]string)
+		for _, id := range ids {
+			res[id] = fmt.Sprint("u", id)
+		}
+		return res, nil
+	}
+	var reported []error
+	report := WithGetErrorCallback(func(_ string, err error) { reported = append(reported, err) })
+
+	// A registered tombstone is a known absence.
+	res, err := MFetch(ctx, c, []int{1, 404}, keyFn, fn, negative, report, WithCorruptPolicy(CorruptFail))
+	require.NoError(t, err)
+	assert.Equal(t, map[int]string{1: "u1"}, res)
+	assert.Equal(t, [][]int{{1}}, loaded)
+
+	// Without the option the tombstone is a miss, not a corrupt entry.
+	res, err = MFetch(ctx, c, []int{1, 404}, keyFn, fn, report, WithCorruptPolicy(CorruptFail))
+	require.NoError(t, err)
+	assert.Equal(t, map[int]string{1: "u1", 404: "u404"}, res)
+	assert.Equal(t, [][]int{{1}, {404}}, loaded)
+	assert.Empty(t, reported)
+}
+
+// failingCache fails every read with err.
+type failingCache struct {
+	*mapCache
+	err error
+}
+
+func (c *failingCache) Get(context.Context, string) ([]byte, error) {
+	return nil, c.err
+}
+
+func TestFetch_ErrorPolicy(t *testing.T) {
+	outage := errors.New("connection refused")
+	c := &failingCache{mapCache: newMapCache(), err: outage}
+	var reported []error
+	report := WithGetErrorCallback(func(_ string, err error) { reported = append(reported, err) })
+	calls := 0
+	fn := func() (int, error) {
+		calls++
+		return 1, nil
+	}
+
+	val, err := Fetch(context.Background(), c, "k", fn, report)
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+	assert.Equal(t, 1, calls)
+
+	_, err = Fetch(context.Background(), c, "k", fn, report, WithErrorPolicy(FailClosed))
+	assert.ErrorIs(t, err, outage)
+	assert.Equal(t, 1, calls)
+
+	require.Len(t, reported, 2)
+	assert.ErrorIs(t, reported[0], outage)
+
+	// A miss is neither reported nor fatal.
+	c.err = ErrCacheMiss
+	_, err = Fetch(context.Background(), c, "k", fn, report, WithErrorPolicy(FailClosed))
+	require.NoError(t, err)
+	assert.Len(t, reported, 2)
+}
+
+func TestFetch_CorruptPolicy(t *testing.T) {
+	ctx := context.Background()
+	c := newMapCache()
+	fn := func() (int, error) { return 1, nil }
+
+	require.NoError(t, c.Set(ctx, "k", []byte("{garbage"), 0))
+	_, err := Fetch(ctx, c, "k", fn, WithCorruptPolicy(CorruptFail))
+	assert.ErrorIs(t, err, ErrCorruptEntry)
+
+	var reported error
+	val, err := Fetch(ctx, c, "k", func() (int, error) {
+		return 0, errors.New("db down")
+	}, WithGetErrorCallback(func(_ string, err error) { reported = err }))
+	assert.Error(t, err)
+	assert.Equal(t, 0, val)
+	assert.ErrorIs(t, reported, ErrCorruptEntry)
+	// The corrupt entry was deleted even though the refill failed.
+	_, err = c.Get(ctx, "k")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+
+	require.NoError(t, c.Set(ctx, "k", []byte("{garbage"), 0))
+	val, err = Fetch(ctx, c, "k", fn)
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+}
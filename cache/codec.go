@@ -0,0 +1,283 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Errors returned by the codecs in this package. Fetch treats them like any
+// other decoding failure, so by default such entries are deleted and
+// refilled (see [WithCorruptPolicy]).
+var (
+	// ErrUnsupportedType is returned by [BinaryCodec] for values it cannot
+	// encode.
+	ErrUnsupportedType = errors.New("cache: unsupported type for codec")
+	// ErrBadEnvelope is returned when data lacks a valid envelope header.
+	ErrBadEnvelope = errors.New("cache: bad envelope")
+	// ErrVersionMismatch is returned for an envelope written with a
+	// different schema version.
+	ErrVersionMismatch = errors.New("cache: schema version mismatch")
+)
+
+// Codec converts values to and from the bytes stored in a [Cache].
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// Built-in codecs.
+var (
+	// JSONCodec encodes values with encoding/json. It is the default used
+	// by [Fetch].
+	JSONCodec Codec = jsonCodec{}
+	// GobCodec encodes values with encoding/gob, which is more compact than
+	// JSON for structs and keeps the exact Go types.
+	GobCodec Codec = gobCodec{}
+	// BinaryCodec encodes []byte and string as is, values implementing
+	// [encoding.BinaryMarshaler] with their own method, and fixed-size
+	// values such as numbers or structs of numbers with encoding/binary in
+	// little-endian order. Plain int and uint are stored as 64 bits; inside
+	// structs or slices they are not supported, since their size depends on
+	// the platform. Other values fail with [ErrUnsupportedType].
+	BinaryCodec Codec = binaryCodec{}
+)
+
+// WithCodec makes [Fetch] encode values with codec. It replaces the
+// functions set with [WithMarshalFunc] and [WithUnmarshalFunc].
+func WithCodec(codec Codec) FetchOption {
+	return func(opts *fetchOptions) {
+		opts.marshalFn = codec.Marshal
+		opts.unmarshalFn = codec.Unmarshal
+	}
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+type binaryCodec struct{}
+
+func (binaryCodec) Marshal(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case []byte:
+		return append([]byte(nil), v...), nil
+	case string:
+		return []byte(v), nil
+	case encoding.BinaryMarshaler:
+		return v.MarshalBinary()
+	case int:
+		return binaryCodec{}.Marshal(int64(v))
+	case uint:
+		return binaryCodec{}.Marshal(uint64(v))
+	}
+	if binary.Size(v) < 0 {
+		return nil, errors.WithStack(ErrUnsupportedType)
+	}
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (binaryCodec) Unmarshal(data []byte, v interface{}) error {
+	switch v := v.(type) {
+	case *[]byte:
+		*v = append([]byte(nil), data...)
+		return nil
+	case *string:
+		*v = string(data)
+		return nil
+	case encoding.BinaryUnmarshaler:
+		return v.UnmarshalBinary(data)
+	case *int:
+		var n int64
+		if err := (binaryCodec{}).Unmarshal(data, &n); err != nil {
+			return err
+		}
+		*v = int(n)
+		return nil
+	case *uint:
+		var n uint64
+		if err := (binaryCodec{}).Unmarshal(data, &n); err != nil {
+			return err
+		}
+		*v = uint(n)
+		return nil
+	}
+	if size := binary.Size(v); size < 0 {
+		return errors.WithStack(ErrUnsupportedType)
+	} else if size != len(data) {
+		return errors.Errorf("cache: binary value has %d bytes, want %d", len(data), size)
+	}
+	return binary.Read(bytes.NewReader(data), binary.LittleEndian, v)
+}
+
+// Compressor compresses encoded values. See [GzipCompressor].
+type Compressor interface {
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+// GzipCompressor is a [Compressor] using compress/gzip at the given level.
+// The zero value uses [gzip.DefaultCompression].
+type GzipCompressor struct {
+	Level int
+}
+
+// Compress implements [Compressor].
+func (g GzipCompressor) Compress(data []byte) ([]byte, error) {
+	level := g.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress implements [Compressor].
+func (GzipCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// The envelope header is a magic byte, the envelope format version, a flags
+// byte and the schema version as a big-endian uint16.
+const (
+	envelopeMagic  = 0xc5
+	envelopeFormat = 1
+	envelopeHeader = 5
+	flagCompressed = 1 << 0
+)
+
+// envelopeOptions holds the resolved configuration for [NewEnvelope].
+type envelopeOptions struct {
+	version    uint16
+	compressor Compressor
+	threshold  int
+}
+
+// EnvelopeOption configures a codec created by [NewEnvelope].
+type EnvelopeOption func(*envelopeOptions)
+
+// WithSchemaVersion sets the schema version written into every entry. Bump
+// it when the cached type changes incompatibly: entries written with any
+// other version are rejected with [ErrVersionMismatch] instead of being
+// mis-decoded. The default is 0.
+func WithSchemaVersion(v uint16) EnvelopeOption {
+	return func(opts *envelopeOptions) {
+		opts.version = v
+	}
+}
+
+// WithCompression compresses encoded values of at least threshold bytes
+// with c. Smaller values are stored as is, since compressing them rarely
+// pays off. Entries record whether they are compressed, so the threshold
+// can change without invalidating the cache.
+func WithCompression(c Compressor, threshold int) EnvelopeOption {
+	return func(opts *envelopeOptions) {
+		opts.compressor = c
+		opts.threshold = threshold
+	}
+}
+
+// envelope is a [Codec] that wraps another one with a versioned header and
+// optional compression.
+type envelope struct {
+	inner Codec
+	opts  envelopeOptions
+}
+
+// NewEnvelope wraps inner in a small versioned header, optionally
+// compressing the payload. Decoding fails with [ErrBadEnvelope] for data
+// without the header, such as entries written before the envelope was
+// introduced.
+func NewEnvelope(inner Codec, opts ...EnvelopeOption) Codec {
+	e := &envelope{inner: inner}
+	for _, opt := range opts {
+		opt(&e.opts)
+	}
+	return e
+}
+
+func (e *envelope) Marshal(v interface{}) ([]byte, error) {
+	payload, err := e.inner.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var flags byte
+	if e.opts.compressor != nil && len(payload) >= e.opts.threshold {
+		if payload, err = e.opts.compressor.Compress(payload); err != nil {
+			return nil, err
+		}
+		flags |= flagCompressed
+	}
+
+	data := make([]byte, envelopeHeader, envelopeHeader+len(payload))
+	data[0] = envelopeMagic
+	data[1] = envelopeFormat
+	data[2] = flags
+	binary.BigEndian.PutUint16(data[3:], e.opts.version)
+	return append(data, payload...), nil
+}
+
+func (e *envelope) Unmarshal(data []byte, v interface{}) error {
+	if len(data) < envelopeHeader || data[0] != envelopeMagic || data[1] != envelopeFormat {
+		return errors.WithStack(ErrBadEnvelope)
+	}
+	if version := binary.BigEndian.Uint16(data[3:]); version != e.opts.version {
+		return errors.Wrapf(ErrVersionMismatch, "got %d, want %d", version, e.opts.version)
+	}
+	payload := data[envelopeHeader:]
+	if data[2]&flagCompressed != 0 {
+		if e.opts.compressor == nil {
+			return errors.Wrap(ErrBadEnvelope, "compressed entry but no compressor")
+		}
+		var err error
+		if payload, err = e.opts.compressor.Decompress(payload); err != nil {
+			return err
+		}
+	}
+	return e.inner.Unmarshal(payload, v)
+}
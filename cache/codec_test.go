@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type point struct {
+	X, Y int32
+}
+
+func TestCodecs(t *testing.T) {
+	for name, codec := range map[string]Codec{
+		"json":   JSONCodec,
+		"gob":    GobCodec,
+		"binary": BinaryCodec,
+		"envelope": NewEnvelope(GobCodec,
+			WithSchemaVersion(3), WithCompression(GzipCompressor{}, 16)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := codec.Marshal(point{X: 1, Y: -2})
+			require.NoError(t, err)
+			var p point
+			require.NoError(t, codec.Unmarshal(data, &p))
+			assert.Equal(t, point{X: 1, Y: -2}, p)
+		})
+	}
+
+	_, err := BinaryCodec.Marshal(map[string]int{})
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestBinaryCodec_Int(t *testing.T) {
+	data, err := BinaryCodec.Marshal(-42)
+	require.NoError(t, err)
+	assert.Len(t, data, 8)
+	var i int
+	require.NoError(t, BinaryCodec.Unmarshal(data, &i))
+	assert.Equal(t, -42, i)
+
+	data, err = BinaryCodec.Marshal(uint(42))
+	require.NoError(t, err)
+	var u uint
+	require.NoError(t, BinaryCodec.Unmarshal(data, &u))
+	assert.Equal(t, uint(42), u)
+
+	c := newMapCache()
+	for _, n := range []int{7, 8} {
+		n := n
+		val, err := Fetch(context.Background(), c, "k", func() (int, error) {
+			return n, nil
+		}, WithCodec(BinaryCodec))
+		require.NoError(t, err)
+		assert.Equal(t, 7, val)
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	codec := NewEnvelope(BinaryCodec, WithSchemaVersion(1), WithCompression(GzipCompressor{}, 64))
+
+	small, err := codec.Marshal("short")
+	require.NoError(t, err)
+	assert.Len(t, small, envelopeHeader+len("short"))
+
+	long := string(bytes.Repeat([]byte("a"), 1024))
+	data, err := codec.Marshal(long)
+	require.NoError(t, err)
+	assert.Less(t, len(data), len(long))
+	var s string
+	require.NoError(t, codec.Unmarshal(data, &s))
+	assert.Equal(t, long, s)
+
+	v2 := NewEnvelope(BinaryCodec, WithSchemaVersion(2), WithCompression(GzipCompressor{}, 64))
+	assert.ErrorIs(t, v2.Unmarshal(data, &s), ErrVersionMismatch)
+	assert.ErrorIs(t, codec.Unmarshal([]byte("plain"), &s), ErrBadEnvelope)
+}
+
+func TestFetch_SchemaVersion(t *testing.T) {
+	ctx := context.Background()
+	c := newMapCache()
+
+	_, err := Fetch(ctx, c, "k", func() (point, error) {
+		return point{X: 1}, nil
+	}, WithCodec(NewEnvelope(JSONCodec, WithSchemaVersion(1))))
+	require.NoError(t, err)
+
+	// A new schema version rejects the old entry and refills it.
+	calls := 0
+	for i := 0; i < 2; i++ {
+		val, err := Fetch(ctx, c, "k", func() (point, error) {
+			calls++
+			return point{X: 2}, nil
+		}, WithCodec(NewEnvelope(JSONCodec, WithSchemaVersion(2))))
+		require.NoError(t, err)
+		assert.Equal(t, point{X: 2}, val)
+	}
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Errors used to classify the outcome of a cache read.
+var (
+	// ErrCacheMiss is returned by [Cache.Get] when the key is not present.
+	// Implementations should return it, or an error wrapping it, so that
+	// [Fetch] can tell a miss from a failing backend.
+	ErrCacheMiss = errors.New("cache: miss")
+	// ErrCorruptEntry is reported when a cached entry cannot be decoded.
+	ErrCorruptEntry = errors.New("cache: corrupt entry")
+)
+
+// ErrorPolicy decides what [Fetch] does when reading from the cache fails
+// with an error other than [ErrCacheMiss].
+type ErrorPolicy int
+
+const (
+	// FailOpen treats the error as a miss and calls fn. It is the default.
+	FailOpen ErrorPolicy = iota
+	// FailClosed returns the error without calling fn, so that a cache
+	// outage does not turn into a flood of requests to the backing store.
+	FailClosed
+)
+
+// CorruptPolicy decides what [Fetch] does with an entry it cannot decode.
+type CorruptPolicy int
+
+const (
+	// CorruptRefill deletes the entry and calls fn to refill it. It is the
+	// default.
+	CorruptRefill CorruptPolicy = iota
+	// CorruptFail returns an error matching [ErrCorruptEntry] and leaves
+	// the entry in place.
+	CorruptFail
+)
+
+// WithErrorPolicy sets how [Fetch] handles cache read errors other than a
+// miss. The default is [FailOpen].
+func WithErrorPolicy(p ErrorPolicy) FetchOption {
+	return func(opts *fetchOptions) {
+		opts.errorPolicy = p
+	}
+}
+
+// WithCorruptPolicy sets how [Fetch] handles entries that cannot be
+// decoded. The default is [CorruptRefill].
+func WithCorruptPolicy(p CorruptPolicy) FetchOption {
+	return func(opts *fetchOptions) {
+		opts.corruptPolicy = p
+	}
+}
+
+// WithGetErrorCallback registers a callback that is invoked when reading
+// key from the cache fails with an error other than a miss, or when the
+// entry is corrupt, whatever the policy. By default such errors are not
+// reported.
+func WithGetErrorCallback(fn func(key string, err error)) FetchOption {
+	return func(opts *fetchOptions) {
+		opts.onGetError = fn
+	}
+}
+
+// read gets key from c and reports whether it was found. A miss returns
+// hit == false and a nil error, and so does a failed read unless the
+// [ErrorPolicy] is [FailClosed]. A hit may have empty data.
+func read(ctx context.Context, c Cache, key string, opts *fetchOptions) (data []byte, hit bool, err error) {
+	data, err = c.Get(ctx, key)
+	if err == nil {
+		return data, true, nil
+	}
+	if errors.Is(err, ErrCacheMiss) {
+		return nil, false, nil
+	}
+	err = errors.WithStack(err)
+	opts.reportGetError(key, err)
+	if opts.errorPolicy == FailClosed {
+		return nil, false, err
+	}
+	return nil, false, nil
+}
+
+// corrupt handles an entry of key that failed to decode with err. It
+// returns a non-nil error only when the [CorruptPolicy] is [CorruptFail];
+// otherwise the entry is deleted so that it can be refilled.
+func corrupt(ctx context.Context, c Cache, key string, err error, opts *fetchOptions) error {
+	err = errors.WithStack(fmt.Errorf("%w: key %q: %v", ErrCorruptEntry, key, err))
+	opts.reportGetError(key, err)
+	if opts.corruptPolicy == CorruptFail {
+		return err
+	}
+	_ = c.Delete(ctx, key)
+	return nil
+}
+
+func (opts *fetchOptions) reportGetError(key string, err error) {
+	if opts.onGetError != nil {
+		opts.onGetError(key, err)
+	}
+}
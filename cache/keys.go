@@ -0,0 +1,178 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// keySeparator joins the parts of a key.
+const keySeparator = ":"
+
+// versionSeq makes version tokens created in the same nanosecond distinct.
+var versionSeq atomic.Uint32
+
+// Adder is implemented by caches that can store a value only if its key is
+// absent. [Namespace] and [Tagged] use it to create version tokens, so that
+// instances starting at the same time agree on a single token.
+type Adder interface {
+	// Add stores val under key unless key already holds a value, and
+	// reports whether it did.
+	Add(ctx context.Context, key string, val []byte, ttl time.Duration) (bool, error)
+}
+
+// namespaceOptions holds the resolved configuration for [NewNamespace].
+type namespaceOptions struct {
+	maxKeyLen int
+}
+
+// NamespaceOption configures a [Namespace] at construction time.
+type NamespaceOption func(*namespaceOptions)
+
+// WithMaxKeyLength sets the length above which keys are hashed. The default
+// is 250, the limit of memcached; 0 disables hashing.
+func WithMaxKeyLength(n int) NamespaceOption {
+	return func(opts *namespaceOptions) {
+		opts.maxKeyLen = n
+	}
+}
+
+// Namespace builds cache keys of the form "name:version:part1:part2..." and
+// can invalidate all of them at once by bumping its version.
+//
+// The version is a token stored in the cache itself under "name:version",
+// so that every instance sharing the cache sees a bump. Entries written
+// under an old version are not deleted; they simply stop being read and
+// expire with their TTL.
+//
+// Every call to [Namespace.Key] reads the version from the cache, which
+// costs one extra round trip per key. When the version does not exist yet,
+// a cache that implements [Adder] makes concurrent creators agree on one
+// token. With any other cache they race: each may briefly use its own
+// token, and entries written under the losing ones are orphaned until they
+// expire.
+type Namespace struct {
+	c    Cache
+	name string
+	opts namespaceOptions
+}
+
+// NewNamespace returns a [Namespace] called name whose version is kept in c.
+func NewNamespace(c Cache, name string, opts ...NamespaceOption) *Namespace {
+	o := namespaceOptions{
+		maxKeyLen: 250,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Namespace{c: c, name: name, opts: o}
+}
+
+// Key returns the key for parts under the current version of n. Parts are
+// formatted with fmt.Sprint and joined with ":", so
+//
+//	ns.Key(ctx, userID, "profile")
+//
+// yields "user:<version>:42:profile" for a namespace called "user". Keys
+// longer than the maximum length keep the namespace and version but replace
+// the parts with their SHA-256 hash.
+func (n *Namespace) Key(ctx context.Context, parts ...interface{}) (string, error) {
+	version, err := n.Version(ctx)
+	if err != nil {
+		return "", err
+	}
+	return n.build(version, parts), nil
+}
+
+// Version returns the current version token of n, creating one if the
+// namespace has none yet or its version was evicted from the cache.
+func (n *Namespace) Version(ctx context.Context) (string, error) {
+	// A lost version must not resurrect entries from before a bump, so
+	// start a fresh one rather than falling back to a fixed default.
+	return loadVersion(ctx, n.c, n.versionKey())
+}
+
+// Bump switches n to a new version, invalidating every key built before.
+func (n *Namespace) Bump(ctx context.Context) error {
+	_, err := n.bump(ctx)
+	return err
+}
+
+func (n *Namespace) bump(ctx context.Context) (string, error) {
+	version := newVersion()
+	if err := n.c.Set(ctx, n.versionKey(), []byte(version), 0); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return version, nil
+}
+
+// loadVersion returns the version token stored under key, creating one if
+// there is none. It uses [Adder] when c implements it, so that concurrent
+// creators end up with the same token.
+func loadVersion(ctx context.Context, c Cache, key string) (string, error) {
+	data, err := c.Get(ctx, key)
+	if err == nil && len(data) > 0 {
+		return string(data), nil
+	}
+	if err != nil && !errors.Is(err, ErrCacheMiss) {
+		return "", errors.WithStack(err)
+	}
+
+	version := newVersion()
+	a, ok := c.(Adder)
+	if !ok {
+		if err := c.Set(ctx, key, []byte(version), 0); err != nil {
+			return "", errors.WithStack(err)
+		}
+		return version, nil
+	}
+	added, err := a.Add(ctx, key, []byte(version), 0)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if added {
+		return version, nil
+	}
+	// Another instance created the token first; use theirs.
+	data, err = c.Get(ctx, key)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return string(data), nil
+}
+
+func (n *Namespace) versionKey() string {
+	return n.name + keySeparator + "version"
+}
+
+func (n *Namespace) build(version string, parts []interface{}) string {
+	strs := make([]string, len(parts))
+	for i, part := range parts {
+		strs[i] = fmt.Sprint(part)
+	}
+	key := n.name + keySeparator + version + keySeparator + strings.Join(strs, keySeparator)
+	if n.opts.maxKeyLen > 0 && len(key) > n.opts.maxKeyLen {
+		return n.name + keySeparator + version + keySeparator + "#" + HashKey(strings.Join(strs, keySeparator))
+	}
+	return key
+}
+
+// HashKey returns the hex-encoded SHA-256 hash of key. It is used to keep
+// long keys within the limits of the backend.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// newVersion returns a version token that differs from every token created
+// before it.
+func newVersion() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 36) + "." + strconv.FormatUint(uint64(versionSeq.Add(1)), 36)
+}
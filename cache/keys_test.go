@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamespace(t *testing.T) {
+	ctx := context.Background()
+	c := newMapCache()
+	ns := NewNamespace(c, "user", WithMaxKeyLength(64))
+
+	k1, err := ns.Key(ctx, 42, "profile")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(k1, "user:"))
+	assert.True(t, strings.HasSuffix(k1, ":42:profile"))
+
+	again, err := ns.Key(ctx, 42, "profile")
+	require.NoError(t, err)
+	assert.Equal(t, k1, again)
+
+	long, err := ns.Key(ctx, strings.Repeat("x", 100))
+	require.NoError(t, err)
+	assert.NotContains(t, long, "xxxx")
+	assert.Contains(t, long, "#"+HashKey(strings.Repeat("x", 100)))
+
+	require.NoError(t, ns.Bump(ctx))
+	k2, err := ns.Key(ctx, 42, "profile")
+	require.NoError(t, err)
+	assert.NotEqual(t, k1, k2)
+}
+
+// adderCache is a [mapCache] with [Adder]. Its first read misses, as if
+// another instance created the entry right after it.
+type adderCache struct {
+	*mapCache
+	missed bool
+}
+
+func (c *adderCache) Get(ctx context.Context, key string) ([]byte, error) {
+	if !c.missed {
+		c.missed = true
+		return nil, ErrCacheMiss
+	}
+	return c.mapCache.Get(ctx, key)
+}
+
+func (c *adderCache) Add(_ context.Context, key string, val []byte, _ time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.data[key]; ok {
+		return false, nil
+	}
+	c.data[key] = val
+	return true, nil
+}
+
+func TestNamespace_Adder(t *testing.T) {
+	ctx := context.Background()
+	c := &adderCache{mapCache: newMapCache()}
+	require.NoError(t, c.Set(ctx, "user:version", []byte("theirs"), 0))
+
+	version, err := NewNamespace(c, "user").Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "theirs", version)
+}
+
+func TestTagged(t *testing.T) {
+	ctx := context.Background()
+	c := NewTagged(newMapCache())
+
+	require.NoError(t, c.SetWithTags(ctx, "a", []byte("1"), time.Minute, "users"))
+	require.NoError(t, c.SetWithTags(ctx, "b", []byte("2"), time.Minute, "users", "orders"))
+	require.NoError(t, c.SetWithTags(ctx, "c", []byte("3"), time.Minute, "orders"))
+	require.NoError(t, c.Set(ctx, "d", []byte("4"), time.Minute))
+
+	require.NoError(t, c.InvalidateTag(ctx, "users"))
+	for key, want := range map[string]bool{"a": false, "b": false, "c": true, "d": true} {
+		_, err := c.Get(ctx, key)
+		if want {
+			assert.NoError(t, err, key)
+		} else {
+			assert.ErrorIs(t, err, ErrCacheMiss, key)
+		}
+	}
+}
+
+func TestFetch_Tags(t *testing.T) {
+	ctx := context.Background()
+	c := NewTagged(newMapCache())
+	calls := 0
+	fn := func() (int, error) {
+		calls++
+		return calls, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		val, err := Fetch(ctx, c, "k", fn, WithTags("t"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, val)
+	}
+	require.NoError(t, c.InvalidateTag(ctx, "t"))
+	val, err := Fetch(ctx, c, "k", fn, WithTags("t"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, val)
+}
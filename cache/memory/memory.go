@@ -0,0 +1,379 @@
+// Package memory provides a concurrency-safe, in-process implementation of
+// [cache.Cache].
+//
+// Entries honor the ttl passed to Set: expired entries are dropped lazily
+// when read and periodically by a background sweeper. The cache can be
+// bounded by the total size of its keys and values, in which case the least
+// recently used entries are evicted to make room. Keys are spread over
+// independently locked shards to reduce contention. The cache also
+// implements [cache.BatchCache] and [cache.Adder].
+//
+// A Cache must be released with [Cache.Close], which stops the sweeper.
+package memory
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/saltfishpr/pkg/cache"
+)
+
+// Errors returned by [Cache]. ErrNotFound matches [cache.ErrCacheMiss].
+var (
+	ErrNotFound = fmt.Errorf("memory: key not found: %w", cache.ErrCacheMiss)
+	ErrTooLarge = errors.New("memory: entry exceeds the size bound")
+	ErrClosed   = errors.New("memory: cache is closed")
+)
+
+var (
+	_ cache.BatchCache = (*Cache)(nil)
+	_ cache.Adder      = (*Cache)(nil)
+)
+
+// options holds the resolved configuration for [New].
+type options struct {
+	shards          int
+	maxBytes        int64
+	cleanupInterval time.Duration
+}
+
+// Option configures a [Cache] at construction time.
+type Option func(*options)
+
+// WithShards sets the number of independently locked shards. The default
+// is 16.
+func WithShards(n int) Option {
+	return func(opts *options) {
+		opts.shards = n
+	}
+}
+
+// WithMaxBytes bounds the total size of keys and values held by the cache.
+// The bound is split evenly between shards, and each shard evicts its least
+// recently used entries when it is exceeded. Because of that split, an
+// entry larger than maxBytes divided by the number of shards is rejected
+// with [ErrTooLarge] even when the cache is empty. A bound smaller than the
+// number of shards reduces the shards to one per byte. The default is 0,
+// meaning no bound.
+func WithMaxBytes(n int64) Option {
+	return func(opts *options) {
+		opts.maxBytes = n
+	}
+}
+
+// WithCleanupInterval sets how often expired entries are swept in the
+// background. The default is 1 minute; 0 disables the sweeper, leaving only
+// the lazy removal on read.
+func WithCleanupInterval(d time.Duration) Option {
+	return func(opts *options) {
+		opts.cleanupInterval = d
+	}
+}
+
+// entry is a stored value and its absolute expiry.
+type entry struct {
+	key      string
+	val      []byte
+	expireAt time.Time // zero means no expiry
+}
+
+func (e *entry) size() int64 {
+	return int64(len(e.key) + len(e.val))
+}
+
+func (e *entry) expired(now time.Time) bool {
+	return !e.expireAt.IsZero() && !now.Before(e.expireAt)
+}
+
+// shard is an LRU-ordered portion of the key space.
+type shard struct {
+	mu       sync.Mutex
+	ll       *list.List
+	items    map[string]*list.Element
+	bytes    int64
+	maxBytes int64
+}
+
+// Cache is an in-memory [cache.Cache]. Create it with [New].
+type Cache struct {
+	shards []*shard
+
+	closeOnce sync.Once
+	closed    chan struct{}
+	wg        sync.WaitGroup
+}
+
+// New creates a [Cache] and, unless disabled, starts its sweeper. It panics
+// if the number of shards is not positive.
+func New(opts ...Option) *Cache {
+	o := options{
+		shards:          16,
+		cleanupInterval: time.Minute,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.shards <= 0 {
+		panic("memory: shards must be positive")
+	}
+	if o.maxBytes > 0 && o.maxBytes < int64(o.shards) {
+		// Keep every shard's share positive, since 0 means unbounded.
+		o.shards = int(o.maxBytes)
+	}
+
+	c := &Cache{
+		shards: make([]*shard, o.shards),
+		closed: make(chan struct{}),
+	}
+	for i := range c.shards {
+		c.shards[i] = &shard{
+			ll:       list.New(),
+			items:    make(map[string]*list.Element),
+			maxBytes: o.maxBytes / int64(o.shards),
+		}
+	}
+	if o.cleanupInterval > 0 {
+		c.wg.Add(1)
+		go c.sweep(o.cleanupInterval)
+	}
+	return c
+}
+
+// Set stores a copy of val under key. A ttl <= 0 means the entry never
+// expires. It returns [ErrTooLarge] if the entry alone exceeds a shard's
+// share of the size bound; any previous value of key is removed then.
+func (c *Cache) Set(_ context.Context, key string, val []byte, ttl time.Duration) error {
+	if c.isClosed() {
+		return ErrClosed
+	}
+	e := &entry{key: key, val: append([]byte(nil), val...)}
+	if ttl > 0 {
+		e.expireAt = time.Now().Add(ttl)
+	}
+	return c.shardFor(key).set(e)
+}
+
+// Add stores a copy of val under key unless key holds an unexpired value,
+// and reports whether it did. The ttl and size bound apply as in Set.
+func (c *Cache) Add(_ context.Context, key string, val []byte, ttl time.Duration) (bool, error) {
+	if c.isClosed() {
+		return false, ErrClosed
+	}
+	now := time.Now()
+	e := &entry{key: key, val: append([]byte(nil), val...)}
+	if ttl > 0 {
+		e.expireAt = now.Add(ttl)
+	}
+	return c.shardFor(key).add(e, now)
+}
+
+// Get returns a copy of the value stored under key, or [ErrNotFound] if it
+// is absent or expired.
+func (c *Cache) Get(_ context.Context, key string) ([]byte, error) {
+	if c.isClosed() {
+		return nil, ErrClosed
+	}
+	val, ok := c.shardFor(key).get(key, time.Now())
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return val, nil
+}
+
+// Delete removes key. Deleting an absent key is not an error.
+func (c *Cache) Delete(_ context.Context, key string) error {
+	if c.isClosed() {
+		return ErrClosed
+	}
+	c.shardFor(key).delete(key)
+	return nil
+}
+
+// MGet returns copies of the values of the keys that are present.
+func (c *Cache) MGet(_ context.Context, keys []string) (map[string][]byte, error) {
+	if c.isClosed() {
+		return nil, ErrClosed
+	}
+	now := time.Now()
+	hits := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		if val, ok := c.shardFor(key).get(key, now); ok {
+			hits[key] = val
+		}
+	}
+	return hits, nil
+}
+
+// MSet stores every item with the same ttl. It stops at the first item
+// that cannot be stored and returns its error.
+func (c *Cache) MSet(ctx context.Context, items map[string][]byte, ttl time.Duration) error {
+	for key, val := range items {
+		if err := c.Set(ctx, key, val, ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Len returns the number of entries, including expired ones that have not
+// been removed yet.
+func (c *Cache) Len() int {
+	n := 0
+	for _, s := range c.shards {
+		s.mu.Lock()
+		n += s.ll.Len()
+		s.mu.Unlock()
+	}
+	return n
+}
+
+// Bytes returns the total size of the keys and values held.
+func (c *Cache) Bytes() int64 {
+	var n int64
+	for _, s := range c.shards {
+		s.mu.Lock()
+		n += s.bytes
+		s.mu.Unlock()
+	}
+	return n
+}
+
+// Close stops the sweeper and drops every entry. Later calls return
+// [ErrClosed]. Calling Close more than once is a no-op.
+func (c *Cache) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.wg.Wait()
+		for _, s := range c.shards {
+			s.mu.Lock()
+			s.ll.Init()
+			s.items = make(map[string]*list.Element)
+			s.bytes = 0
+			s.mu.Unlock()
+		}
+	})
+	return nil
+}
+
+func (c *Cache) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// shardFor hashes key with 32-bit FNV-1a to pick its shard.
+func (c *Cache) shardFor(key string) *shard {
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return c.shards[h%uint32(len(c.shards))]
+}
+
+// sweep removes expired entries every interval until the cache is closed.
+func (c *Cache) sweep(interval time.Duration) {
+	defer c.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.closed:
+			return
+		case now := <-ticker.C:
+			for _, s := range c.shards {
+				s.removeExpired(now)
+			}
+		}
+	}
+}
+
+func (s *shard) set(e *entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.items[e.key]; ok {
+		s.removeElement(elem)
+	}
+	if s.maxBytes > 0 && e.size() > s.maxBytes {
+		return ErrTooLarge
+	}
+	s.insert(e)
+	return nil
+}
+
+func (s *shard) add(e *entry, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.items[e.key]; ok {
+		if !elem.Value.(*entry).expired(now) {
+			return false, nil
+		}
+		s.removeElement(elem)
+	}
+	if s.maxBytes > 0 && e.size() > s.maxBytes {
+		return false, ErrTooLarge
+	}
+	s.insert(e)
+	return true, nil
+}
+
+// insert adds e, which must not be present, and evicts the least recently
+// used entries beyond the size bound.
+func (s *shard) insert(e *entry) {
+	s.items[e.key] = s.ll.PushFront(e)
+	s.bytes += e.size()
+	for s.maxBytes > 0 && s.bytes > s.maxBytes {
+		s.removeElement(s.ll.Back())
+	}
+}
+
+func (s *shard) get(key string, now time.Time) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := elem.Value.(*entry)
+	if e.expired(now) {
+		s.removeElement(elem)
+		return nil, false
+	}
+	s.ll.MoveToFront(elem)
+	// Copy into a non-nil slice so that an empty value reads as one.
+	return append([]byte{}, e.val...), true
+}
+
+func (s *shard) delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.items[key]; ok {
+		s.removeElement(elem)
+	}
+}
+
+func (s *shard) removeExpired(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for elem := s.ll.Front(); elem != nil; {
+		next := elem.Next()
+		if elem.Value.(*entry).expired(now) {
+			s.removeElement(elem)
+		}
+		elem = next
+	}
+}
+
+// removeElement removes an element from both the list and the map.
+func (s *shard) removeElement(elem *list.Element) {
+	e := s.ll.Remove(elem).(*entry)
+	delete(s.items, e.key)
+	s.bytes -= e.size()
+}
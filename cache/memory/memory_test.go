@@ -0,0 +1,182 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/saltfishpr/pkg/cache"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	c := New()
+	defer c.Close()
+
+	_, err := c.Get(ctx, "k")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, err, cache.ErrCacheMiss)
+
+	val := []byte("v")
+	require.NoError(t, c.Set(ctx, "k", val, 0))
+	val[0] = 'x' // the cache keeps its own copy
+	got, err := c.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("v"), got)
+
+	require.NoError(t, c.Delete(ctx, "k"))
+	_, err = c.Get(ctx, "k")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestCache_TTL(t *testing.T) {
+	ctx := context.Background()
+	c := New(WithCleanupInterval(5 * time.Millisecond))
+	defer c.Close()
+
+	require.NoError(t, c.Set(ctx, "lazy", []byte("v"), 10*time.Millisecond))
+	require.NoError(t, c.Set(ctx, "forever", []byte("v"), 0))
+	time.Sleep(20 * time.Millisecond)
+
+	_, err := c.Get(ctx, "lazy")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = c.Get(ctx, "forever")
+	assert.NoError(t, err)
+
+	// The sweeper removes expired entries that are never read.
+	require.NoError(t, c.Set(ctx, "swept", []byte("v"), 10*time.Millisecond))
+	assert.Eventually(t, func() bool { return c.Len() == 1 }, time.Second, 5*time.Millisecond)
+}
+
+func TestCache_MaxBytes(t *testing.T) {
+	ctx := context.Background()
+	c := New(WithShards(1), WithMaxBytes(20), WithCleanupInterval(0))
+	defer c.Close()
+
+	// Each entry takes 2+8 bytes, so only two fit.
+	require.NoError(t, c.Set(ctx, "k1", []byte("12345678"), 0))
+	require.NoError(t, c.Set(ctx, "k2", []byte("12345678"), 0))
+	_, err := c.Get(ctx, "k1") // k2 becomes least recently used
+	require.NoError(t, err)
+	require.NoError(t, c.Set(ctx, "k3", []byte("12345678"), 0))
+
+	_, err = c.Get(ctx, "k2")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, int64(20), c.Bytes())
+	assert.ErrorIs(t, c.Set(ctx, "big", make([]byte, 32), 0), ErrTooLarge)
+
+	// A rejected overwrite does not leave the old value behind.
+	assert.ErrorIs(t, c.Set(ctx, "k1", make([]byte, 32), 0), ErrTooLarge)
+	_, err = c.Get(ctx, "k1")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, int64(10), c.Bytes())
+}
+
+func TestCache_MaxBytesBelowShards(t *testing.T) {
+	ctx := context.Background()
+	c := New(WithMaxBytes(4), WithCleanupInterval(0))
+	defer c.Close()
+
+	// The bound still applies instead of rounding down to unbounded.
+	assert.Len(t, c.shards, 4)
+	assert.ErrorIs(t, c.Set(ctx, "key", []byte("value"), 0), ErrTooLarge)
+	for i := 0; i < 16; i++ {
+		_ = c.Set(ctx, string(rune('a'+i)), nil, 0)
+	}
+	assert.LessOrEqual(t, c.Bytes(), int64(4))
+}
+
+func TestCache_Add(t *testing.T) {
+	ctx := context.Background()
+	c := New(WithCleanupInterval(0))
+	defer c.Close()
+
+	added, err := c.Add(ctx, "k", []byte("v1"), 10*time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, added)
+	added, err = c.Add(ctx, "k", []byte("v2"), 0)
+	require.NoError(t, err)
+	assert.False(t, added)
+
+	// An expired entry counts as absent.
+	time.Sleep(20 * time.Millisecond)
+	added, err = c.Add(ctx, "k", []byte("v3"), 0)
+	require.NoError(t, err)
+	assert.True(t, added)
+	val, err := c.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("v3"), val)
+}
+
+func TestCache_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	c := New(WithMaxBytes(1 << 10))
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				key := fmt.Sprintf("k%d", (i*200+j)%50)
+				_ = c.Set(ctx, key, []byte(key), time.Minute)
+				_, _ = c.Get(ctx, key)
+				if j%10 == 0 {
+					_ = c.Delete(ctx, key)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, c.Bytes(), int64(1<<10))
+}
+
+func TestCache_Fetch(t *testing.T) {
+	ctx := context.Background()
+	c := New()
+	defer c.Close()
+
+	calls := 0
+	for i := 0; i < 2; i++ {
+		val, err := cache.Fetch(ctx, c, "k", func() (string, error) {
+			calls++
+			return "v", nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "v", val)
+	}
+	assert.Equal(t, 1, calls)
+
+	// An empty value is a hit like any other.
+	for i := 0; i < 2; i++ {
+		val, err := cache.Fetch(ctx, c, "empty", func() (string, error) {
+			calls++
+			return "", nil
+		}, cache.WithCodec(cache.BinaryCodec))
+		require.NoError(t, err)
+		assert.Equal(t, "", val)
+	}
+	assert.Equal(t, 2, calls)
+	val, err := c.Get(ctx, "empty")
+	require.NoError(t, err)
+	assert.NotNil(t, val)
+}
+
+func TestCache_Close(t *testing.T) {
+	c := New()
+	require.NoError(t, c.Close())
+	require.NoError(t, c.Close())
+	assert.ErrorIs(t, c.Set(context.Background(), "k", nil, 0), ErrClosed)
+}
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"bytes"
+	"time"
+)
+
+// tombstonePrefix marks a cached negative result. The default JSON encoding
+// never produces a leading NUL byte, so it cannot collide with a value.
+var tombstonePrefix = []byte("\x00cache:tombstone\x00")
+
+// negativeClass is an error class registered with [WithNegativeCache].
+type negativeClass struct {
+	err   error
+	match func(error) bool
+	ttl   time.Duration
+}
+
+// matchNegative returns the first class in classes that err belongs to.
+func matchNegative(classes []negativeClass, err error) (negativeClass, bool) {
+	for _, class := range classes {
+		if class.match(err) {
+			return class, true
+		}
+	}
+	return negativeClass{}, false
+}
+
+// encodeTombstone returns the cached form of a negative result of class.
+// The class is identified by its message so that entries survive changes in
+// the order of options.
+func encodeTombstone(class negativeClass) []byte {
+	return append(append([]byte{}, tombstonePrefix...), class.err.Error()...)
+}
+
+// decodeTombstone reports whether data is a tombstone and, if so, the
+// registered class it refers to. A tombstone whose class is no longer
+// registered yields ok == true with a nil error and is treated as a miss.
+func decodeTombstone(classes []negativeClass, data []byte) (err error, ok bool) {
+	if !bytes.HasPrefix(data, tombstonePrefix) {
+		return nil, false
+	}
+	msg := string(data[len(tombstonePrefix):])
+	for _, class := range classes {
+		if class.err.Error() == msg {
+			return class.err, true
+		}
+	}
+	return nil, true
+}
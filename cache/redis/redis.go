@@ -0,0 +1,434 @@
+// Package redis implements [cache.Cache] on top of a Redis server, speaking
+// the RESP protocol directly so that no client library is needed.
+//
+// A missing key is reported as [ErrMiss], which matches [cache.ErrCacheMiss],
+// while network failures surface as the underlying transport errors and
+// error replies from the server as [Error], so callers such as [cache.Fetch]
+// can tell a miss from an outage.
+// Besides the [cache.Cache] methods, [Client] implements [cache.BatchCache]
+// and [cache.Adder], and offers TTL inspection and renewal.
+package redis
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/saltfishpr/pkg/cache"
+)
+
+// Errors returned by [Client]. ErrMiss matches [cache.ErrCacheMiss].
+var (
+	ErrMiss   = errors.WithMessage(cache.ErrCacheMiss, "redis: key not found")
+	ErrClosed = errors.New("redis: client is closed")
+	// ErrInvalidTTL is returned by [Client.Expire] for a ttl that is not
+	// positive, which Redis would treat as a request to delete the key.
+	ErrInvalidTTL = errors.New("redis: ttl must be positive")
+)
+
+var (
+	_ cache.BatchCache = (*Client)(nil)
+	_ cache.Adder      = (*Client)(nil)
+)
+
+// options holds the resolved configuration for [New].
+type options struct {
+	poolSize    int
+	dialTimeout time.Duration
+	password    string
+	db          int
+}
+
+// Option configures a [Client] at construction time.
+type Option func(*options)
+
+// WithPoolSize sets how many idle connections are kept for reuse. The
+// default is 8.
+func WithPoolSize(n int) Option {
+	return func(opts *options) {
+		opts.poolSize = n
+	}
+}
+
+// WithDialTimeout bounds how long establishing a connection may take. The
+// default is 5 seconds.
+func WithDialTimeout(d time.Duration) Option {
+	return func(opts *options) {
+		opts.dialTimeout = d
+	}
+}
+
+// WithPassword makes every new connection authenticate with AUTH.
+func WithPassword(password string) Option {
+	return func(opts *options) {
+		opts.password = password
+	}
+}
+
+// WithDB makes every new connection SELECT the given database. The default
+// is database 0.
+func WithDB(db int) Option {
+	return func(opts *options) {
+		opts.db = db
+	}
+}
+
+// conn is a single connection to the server.
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+// Client is a Redis-backed [cache.Cache]. It is safe for concurrent use;
+// each command borrows a connection from a small pool.
+type Client struct {
+	addr string
+	opts options
+
+	mu     sync.Mutex
+	idle   []*conn
+	closed bool
+}
+
+// New creates a [Client] for the server at addr ("host:port"). Connections
+// are established lazily.
+func New(addr string, opts ...Option) *Client {
+	o := options{
+		poolSize:    8,
+		dialTimeout: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Client{addr: addr, opts: o}
+}
+
+// Get returns the value of key, or [ErrMiss] if it does not exist.
+func (c *Client) Get(ctx context.Context, key string) ([]byte, error) {
+	reply, err := c.do(ctx, "GET", key)
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, ErrMiss
+	}
+	val, ok := reply.([]byte)
+	if !ok {
+		return nil, errors.WithStack(errProtocol)
+	}
+	return val, nil
+}
+
+// Set stores val under key. A ttl <= 0 means the key never expires.
+func (c *Client) Set(ctx context.Context, key string, val []byte, ttl time.Duration) error {
+	_, err := c.do(ctx, setArgs(key, val, ttl)...)
+	return err
+}
+
+// Add stores val under key unless it already exists, using SET NX, and
+// reports whether it did.
+func (c *Client) Add(ctx context.Context, key string, val []byte, ttl time.Duration) (bool, error) {
+	reply, err := c.do(ctx, append(setArgs(key, val, ttl), "NX")...)
+	if err != nil {
+		return false, err
+	}
+	return reply != nil, nil
+}
+
+// Delete removes key. Deleting an absent key is not an error.
+func (c *Client) Delete(ctx context.Context, key string) error {
+	_, err := c.do(ctx, "DEL", key)
+	return err
+}
+
+// MGet returns the values of the keys that exist, using a single MGET.
+func (c *Client) MGet(ctx context.Context, keys []string) (map[string][]byte, error) {
+	if len(keys) == 0 {
+		return map[string][]byte{}, nil
+	}
+	reply, err := c.do(ctx, append([]string{"MGET"}, keys...)...)
+	if err != nil {
+		return nil, err
+	}
+	vals, ok := reply.([]interface{})
+	if !ok || len(vals) != len(keys) {
+		return nil, errors.WithStack(errProtocol)
+	}
+	hits := make(map[string][]byte, len(keys))
+	for i, v := range vals {
+		if val, ok := v.([]byte); ok {
+			hits[keys[i]] = val
+		}
+	}
+	return hits, nil
+}
+
+// MSet stores every item with the same ttl. MSET cannot set expiries, so
+// the items are written as pipelined SET commands in one round trip.
+func (c *Client) MSet(ctx context.Context, items map[string][]byte, ttl time.Duration) error {
+	if len(items) == 0 {
+		return nil
+	}
+	cmds := make([][]string, 0, len(items))
+	for key, val := range items {
+		cmds = append(cmds, setArgs(key, val, ttl))
+	}
+	replies, err := c.pipeline(ctx, cmds)
+	if err != nil {
+		return err
+	}
+	for _, reply := range replies {
+		if err, ok := reply.(Error); ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// TTL returns the remaining time to live of key. It returns -1 for a key
+// without expiry and [ErrMiss] if the key does not exist.
+func (c *Client) TTL(ctx context.Context, key string) (time.Duration, error) {
+	reply, err := c.do(ctx, "PTTL", key)
+	if err != nil {
+		return 0, err
+	}
+	ms, ok := reply.(int64)
+	if !ok {
+		return 0, errors.WithStack(errProtocol)
+	}
+	switch {
+	case ms == -2:
+		return 0, ErrMiss
+	case ms < 0:
+		return -1, nil
+	default:
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+}
+
+// Expire sets the time to live of an existing key, rounded up to whole
+// milliseconds. It returns [ErrMiss] if the key does not exist and
+// [ErrInvalidTTL] if ttl is not positive; use Delete to remove a key.
+func (c *Client) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return errors.WithStack(ErrInvalidTTL)
+	}
+	reply, err := c.do(ctx, "PEXPIRE", key, milliseconds(ttl))
+	if err != nil {
+		return err
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return errors.WithStack(errProtocol)
+	}
+	if n == 0 {
+		return ErrMiss
+	}
+	return nil
+}
+
+// Ping checks that the server is reachable.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.do(ctx, "PING")
+	return err
+}
+
+// Close closes the idle connections. Commands issued afterwards fail with
+// [ErrClosed]; connections in use are closed when they are returned.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	idle := c.idle
+	c.idle = nil
+	c.closed = true
+	c.mu.Unlock()
+
+	var first error
+	for _, cn := range idle {
+		if err := cn.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// setArgs builds a SET command with an optional millisecond expiry.
+func setArgs(key string, val []byte, ttl time.Duration) []string {
+	args := []string{"SET", key, string(val)}
+	if ttl > 0 {
+		args = append(args, "PX", milliseconds(ttl))
+	}
+	return args
+}
+
+// milliseconds formats a positive ttl in milliseconds, rounding values
+// below one millisecond up so that they do not expire the key at once.
+func milliseconds(ttl time.Duration) string {
+	ms := ttl.Milliseconds()
+	if ms == 0 {
+		ms = 1
+	}
+	return strconv.FormatInt(ms, 10)
+}
+
+// do sends a single command and returns its reply. An error reply is
+// returned as the error.
+func (c *Client) do(ctx context.Context, args ...string) (interface{}, error) {
+	replies, err := c.pipeline(ctx, [][]string{args})
+	if err != nil {
+		return nil, err
+	}
+	if err, ok := replies[0].(Error); ok {
+		return nil, err
+	}
+	return replies[0], nil
+}
+
+// pipeline sends cmds on one connection and reads one reply per command.
+// Error replies are left in the result for the caller to inspect.
+func (c *Client) pipeline(ctx context.Context, cmds [][]string) ([]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	replies, err := cn.roundTrip(ctx, cmds)
+	if err != nil {
+		// The stream may be out of sync with the server; don't reuse it.
+		_ = cn.Close()
+		return nil, err
+	}
+	c.put(cn)
+	return replies, nil
+}
+
+// get returns an idle connection or dials a new one.
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, ErrClosed
+	}
+	if n := len(c.idle); n > 0 {
+		cn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return cn, nil
+	}
+	c.mu.Unlock()
+	return c.dial(ctx)
+}
+
+// put returns cn to the pool, or closes it if the pool is full or closed.
+func (c *Client) put(cn *conn) {
+	c.mu.Lock()
+	if !c.closed && len(c.idle) < c.opts.poolSize {
+		c.idle = append(c.idle, cn)
+		c.mu.Unlock()
+		return
+	}
+	c.mu.Unlock()
+	_ = cn.Close()
+}
+
+// dial opens a connection and runs the AUTH and SELECT handshake.
+func (c *Client) dial(ctx context.Context) (*conn, error) {
+	d := net.Dialer{Timeout: c.opts.dialTimeout}
+	nc, err := d.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	cn := &conn{Conn: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}
+
+	var cmds [][]string
+	if c.opts.password != "" {
+		cmds = append(cmds, []string{"AUTH", c.opts.password})
+	}
+	if c.opts.db != 0 {
+		cmds = append(cmds, []string{"SELECT", strconv.Itoa(c.opts.db)})
+	}
+	if len(cmds) > 0 {
+		replies, err := cn.roundTrip(ctx, cmds)
+		if err == nil {
+			for _, reply := range replies {
+				if rerr, ok := reply.(Error); ok {
+					err = rerr
+					break
+				}
+			}
+		}
+		if err != nil {
+			_ = cn.Close()
+			return nil, err
+		}
+	}
+	return cn, nil
+}
+
+// roundTrip writes cmds and reads their replies, honoring ctx's deadline
+// and cancellation. It returns ctx.Err() if ctx ends first.
+func (cn *conn) roundTrip(ctx context.Context, cmds [][]string) ([]interface{}, error) {
+	deadline, _ := ctx.Deadline()
+	if err := cn.SetDeadline(deadline); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer cn.watch(ctx)()
+
+	replies, err := cn.exchange(cmds)
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return replies, err
+}
+
+// watch interrupts blocked I/O on cn when ctx is cancelled, by moving the
+// deadline to now. The returned function stops watching and must be called
+// before cn is reused.
+func (cn *conn) watch(ctx context.Context) func() {
+	if ctx.Done() == nil {
+		return func() {}
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		select {
+		case <-ctx.Done():
+			_ = cn.SetDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+	return func() {
+		close(stop)
+		<-done
+	}
+}
+
+// exchange writes cmds and reads one reply per command.
+func (cn *conn) exchange(cmds [][]string) ([]interface{}, error) {
+	for _, args := range cmds {
+		if err := writeCommand(cn.w, args); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if err := cn.w.Flush(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	replies := make([]interface{}, len(cmds))
+	for i := range replies {
+		reply, err := readReply(cn.r)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		replies[i] = reply
+	}
+	return replies, nil
+}
@@ -0,0 +1,186 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/saltfishpr/pkg/cache"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func newTestClient(t *testing.T, opts ...Option) (*Client, *testServer) {
+	t.Helper()
+	s := newTestServer(t, "")
+	c := New(s.addr(), opts...)
+	t.Cleanup(func() { _ = c.Close() })
+	return c, s
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestClient(t)
+
+	require.NoError(t, c.Ping(ctx))
+
+	_, err := c.Get(ctx, "k")
+	assert.ErrorIs(t, err, ErrMiss)
+	assert.ErrorIs(t, err, cache.ErrCacheMiss)
+
+	require.NoError(t, c.Set(ctx, "k", []byte("a\r\nb"), 0))
+	val, err := c.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("a\r\nb"), val)
+
+	require.NoError(t, c.Delete(ctx, "k"))
+	_, err = c.Get(ctx, "k")
+	assert.ErrorIs(t, err, ErrMiss)
+	require.NoError(t, c.Delete(ctx, "k"))
+}
+
+func TestClient_TTL(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestClient(t)
+
+	require.NoError(t, c.Set(ctx, "k", []byte("v"), time.Minute))
+	ttl, err := c.TTL(ctx, "k")
+	require.NoError(t, err)
+	assert.InDelta(t, time.Minute, ttl, float64(time.Second))
+
+	require.NoError(t, c.Expire(ctx, "k", 10*time.Millisecond))
+	time.Sleep(20 * time.Millisecond)
+	_, err = c.Get(ctx, "k")
+	assert.ErrorIs(t, err, ErrMiss)
+	_, err = c.TTL(ctx, "k")
+	assert.ErrorIs(t, err, ErrMiss)
+	assert.ErrorIs(t, c.Expire(ctx, "k", time.Minute), ErrMiss)
+
+	// Non-positive ttls are rejected rather than deleting the key, and
+	// sub-millisecond ones are rounded up.
+	require.NoError(t, c.Set(ctx, "k", []byte("v"), time.Minute))
+	assert.ErrorIs(t, c.Expire(ctx, "k", 0), ErrInvalidTTL)
+	assert.ErrorIs(t, c.Expire(ctx, "k", -time.Second), ErrInvalidTTL)
+	_, err = c.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "1", milliseconds(time.Microsecond))
+	assert.Equal(t, "1500", milliseconds(1500*time.Millisecond))
+
+	require.NoError(t, c.Set(ctx, "forever", []byte("v"), 0))
+	ttl, err = c.TTL(ctx, "forever")
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(-1), ttl)
+}
+
+func TestClient_Add(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestClient(t)
+
+	added, err := c.Add(ctx, "k", []byte("v1"), time.Minute)
+	require.NoError(t, err)
+	assert.True(t, added)
+	added, err = c.Add(ctx, "k", []byte("v2"), time.Minute)
+	require.NoError(t, err)
+	assert.False(t, added)
+	val, err := c.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("v1"), val)
+}
+
+func TestClient_Batch(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestClient(t)
+
+	require.NoError(t, c.MSet(ctx, map[string][]byte{
+		"a": []byte("1"),
+		"b": []byte("2"),
+	}, time.Minute))
+	hits, err := c.MGet(ctx, []string{"a", "missing", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"a": []byte("1"), "b": []byte("2")}, hits)
+}
+
+func TestClient_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	// An error reply is not a miss, and the connection stays usable.
+	c, _ := newTestClient(t)
+	_, err := c.do(ctx, "BOGUS")
+	var rerr Error
+	require.ErrorAs(t, err, &rerr)
+	assert.NotErrorIs(t, err, ErrMiss)
+	require.NoError(t, c.Ping(ctx))
+
+	// A transport failure is not a miss either.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+	_, err = New(addr).Get(ctx, "k")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, cache.ErrCacheMiss)
+
+	require.NoError(t, c.Close())
+	assert.ErrorIs(t, c.Ping(ctx), ErrClosed)
+}
+
+func TestClient_Cancel(t *testing.T) {
+	// A server that accepts connections but never replies.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if nc, err := ln.Accept(); err == nil {
+			accepted <- nc
+		}
+	}()
+
+	c := New(ln.Addr().String())
+	defer c.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err = c.Get(ctx, "k")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), time.Second)
+	(<-accepted).Close()
+}
+
+func TestClient_Auth(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(t, "secret")
+
+	c := New(s.addr(), WithPassword("secret"), WithDB(1))
+	defer c.Close()
+	require.NoError(t, c.Ping(ctx))
+
+	bad := New(s.addr(), WithPassword("wrong"))
+	defer bad.Close()
+	var rerr Error
+	assert.ErrorAs(t, bad.Ping(ctx), &rerr)
+}
+
+func TestClient_Fetch(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestClient(t)
+
+	calls := 0
+	for i := 0; i < 2; i++ {
+		val, err := cache.Fetch(ctx, c, "k", func() (string, error) {
+			calls++
+			return "v", nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "v", val)
+	}
+	assert.Equal(t, 1, calls)
+}
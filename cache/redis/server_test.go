@@ -0,0 +1,205 @@
+package redis
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testServer is an in-process stand-in for Redis that understands the few
+// commands used by [Client].
+type testServer struct {
+	ln       net.Listener
+	password string
+	wg       sync.WaitGroup
+
+	mu    sync.Mutex
+	data  map[string][]byte
+	exp   map[string]time.Time
+	conns map[net.Conn]struct{}
+}
+
+// newTestServer starts a server on a random local port and stops it when
+// the test ends.
+func newTestServer(t *testing.T, password string) *testServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testServer{
+		ln:       ln,
+		password: password,
+		data:     make(map[string][]byte),
+		exp:      make(map[string]time.Time),
+		conns:    make(map[net.Conn]struct{}),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	t.Cleanup(s.close)
+	return s
+}
+
+func (s *testServer) addr() string {
+	return s.ln.Addr().String()
+}
+
+func (s *testServer) close() {
+	_ = s.ln.Close()
+	s.mu.Lock()
+	for c := range s.conns {
+		_ = c.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *testServer) serve() {
+	defer s.wg.Done()
+	for {
+		c, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[c] = struct{}{}
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go s.handle(c)
+	}
+}
+
+func (s *testServer) handle(c net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		_ = c.Close()
+	}()
+
+	r := bufio.NewReader(c)
+	w := bufio.NewWriter(c)
+	authed := s.password == ""
+	for {
+		reply, err := readReply(r)
+		if err != nil {
+			return
+		}
+		items, ok := reply.([]interface{})
+		if !ok || len(items) == 0 {
+			return
+		}
+		args := make([]string, len(items))
+		for i, item := range items {
+			b, _ := item.([]byte)
+			args[i] = string(b)
+		}
+
+		cmd := strings.ToUpper(args[0])
+		switch {
+		case cmd == "AUTH":
+			authed = len(args) == 2 && args[1] == s.password
+			if authed {
+				fmt.Fprint(w, "+OK\r\n")
+			} else {
+				fmt.Fprint(w, "-WRONGPASS invalid password\r\n")
+			}
+		case !authed:
+			fmt.Fprint(w, "-NOAUTH Authentication required.\r\n")
+		default:
+			s.exec(w, cmd, args[1:])
+		}
+		if r.Buffered() == 0 {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *testServer) exec(w *bufio.Writer, cmd string, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch cmd {
+	case "PING":
+		fmt.Fprint(w, "+PONG\r\n")
+	case "SELECT":
+		fmt.Fprint(w, "+OK\r\n")
+	case "GET":
+		writeBulk(w, s.lookup(args[0]))
+	case "SET":
+		opts := args[2:]
+		if n := len(opts); n > 0 && strings.ToUpper(opts[n-1]) == "NX" {
+			if s.lookup(args[0]) != nil {
+				writeBulk(w, nil)
+				return
+			}
+			opts = opts[:n-1]
+		}
+		s.data[args[0]] = []byte(args[1])
+		delete(s.exp, args[0])
+		if len(opts) == 2 && strings.ToUpper(opts[0]) == "PX" {
+			ms, _ := strconv.Atoi(opts[1])
+			s.exp[args[0]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		}
+		fmt.Fprint(w, "+OK\r\n")
+	case "DEL":
+		n := 0
+		for _, key := range args {
+			if s.lookup(key) != nil {
+				n++
+			}
+			delete(s.data, key)
+			delete(s.exp, key)
+		}
+		fmt.Fprintf(w, ":%d\r\n", n)
+	case "MGET":
+		fmt.Fprintf(w, "*%d\r\n", len(args))
+		for _, key := range args {
+			writeBulk(w, s.lookup(key))
+		}
+	case "PTTL":
+		switch exp, ok := s.exp[args[0]]; {
+		case s.lookup(args[0]) == nil:
+			fmt.Fprint(w, ":-2\r\n")
+		case !ok:
+			fmt.Fprint(w, ":-1\r\n")
+		default:
+			fmt.Fprintf(w, ":%d\r\n", time.Until(exp).Milliseconds())
+		}
+	case "PEXPIRE":
+		if s.lookup(args[0]) == nil {
+			fmt.Fprint(w, ":0\r\n")
+			return
+		}
+		ms, _ := strconv.Atoi(args[1])
+		s.exp[args[0]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		fmt.Fprint(w, ":1\r\n")
+	default:
+		fmt.Fprintf(w, "-ERR unknown command '%s'\r\n", cmd)
+	}
+}
+
+// lookup returns the live value of key, dropping it if it has expired.
+func (s *testServer) lookup(key string) []byte {
+	if exp, ok := s.exp[key]; ok && !time.Now().Before(exp) {
+		delete(s.data, key)
+		delete(s.exp, key)
+	}
+	return s.data[key]
+}
+
+func writeBulk(w *bufio.Writer, val []byte) {
+	if val == nil {
+		fmt.Fprint(w, "$-1\r\n")
+		return
+	}
+	fmt.Fprintf(w, "$%d\r\n%s\r\n", len(val), val)
+}
@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/saltfishpr/pkg/routine"
+)
+
+// ErrFlightTimeout is returned by [Fetch] when a caller waited longer than
+// the timeout set with [WithFlightTimeout] for a shared load to finish.
+var ErrFlightTimeout = errors.New("cache: singleflight timeout")
+
+// Group collapses concurrent loads of the same key into a single call. It is
+// used by [Fetch] through [WithSingleflight]; share one Group between all
+// Fetch calls that should be deduplicated against each other. The zero
+// value is ready to use.
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// call is an in-flight or completed load shared by every caller of a key.
+type call struct {
+	done chan struct{}
+	val  interface{}
+	err  error
+}
+
+// Forget drops the in-flight load for key, if any, so that the next caller
+// starts a new one. Callers already waiting still receive its result.
+func (g *Group) Forget(key string) {
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+}
+
+// do runs fn once for all concurrent callers of key and returns its result.
+//
+// fn runs in its own goroutine with a context that is detached from the
+// caller's cancellation, so a cancelled caller never fails the load for the
+// others; it just stops waiting and returns ctx.Err(). A positive timeout
+// bounds both the wait and the context passed to fn. When a caller times
+// out the load is forgotten, so a stuck fn does not block the key forever.
+func (g *Group) do(ctx context.Context, key string, timeout time.Duration, fn func(context.Context) (interface{}, error)) (interface{}, error) {
+	c, _ := g.start(ctx, key, timeout, fn)
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case <-c.done:
+		return c.val, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-expired:
+		g.forget(key, c)
+		return nil, errors.WithStack(ErrFlightTimeout)
+	}
+}
+
+// start joins the load in flight for key, or starts one with fn. It reports
+// whether a new load was started.
+func (g *Group) start(ctx context.Context, key string, timeout time.Duration, fn func(context.Context) (interface{}, error)) (*call, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	if c, ok := g.calls[key]; ok {
+		return c, false
+	}
+	c := &call{done: make(chan struct{})}
+	g.calls[key] = c
+	go g.run(ctx, key, c, timeout, fn)
+	return c, true
+}
+
+// run executes fn for c and publishes its result to every waiter.
+func (g *Group) run(ctx context.Context, key string, c *call, timeout time.Duration, fn func(context.Context) (interface{}, error)) {
+	ctx = detach(ctx)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			c.err = routine.NewRecovered(2, r).AsError()
+		}
+		g.forget(key, c)
+		close(c.done)
+	}()
+	c.val, c.err = fn(ctx)
+}
+
+// forget removes key from g only if it still maps to c.
+func (g *Group) forget(key string, c *call) {
+	g.mu.Lock()
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+	g.mu.Unlock()
+}
+
+// detachedContext keeps the values of its parent but none of its deadline
+// or cancellation.
+type detachedContext struct {
+	parent context.Context
+}
+
+func detach(ctx context.Context) context.Context {
+	return detachedContext{parent: ctx}
+}
+
+func (detachedContext) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}               { return nil }
+func (detachedContext) Err() error                          { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// staleHeader marks a value stored together with its soft expiry. It is
+// followed by the soft expiry in Unix nanoseconds and the time fn took to
+// compute the value, both as big-endian int64.
+var staleHeader = []byte("\x00cache:swr\x00")
+
+const staleHeaderLen = 16
+
+// refreshes deduplicates background refreshes for Fetch calls that do not
+// use [WithSingleflight].
+var refreshes Group
+
+// staleEntry is a decoded value with its refresh metadata.
+type staleEntry struct {
+	softExpiry time.Time
+	delta      time.Duration // time taken by the load that produced the value
+	data       []byte
+}
+
+// encodeStale prepends the soft expiry and compute time to data.
+func encodeStale(data []byte, softExpiry time.Time, delta time.Duration) []byte {
+	buf := make([]byte, len(staleHeader)+staleHeaderLen, len(staleHeader)+staleHeaderLen+len(data))
+	copy(buf, staleHeader)
+	binary.BigEndian.PutUint64(buf[len(staleHeader):], uint64(softExpiry.UnixNano()))
+	binary.BigEndian.PutUint64(buf[len(staleHeader)+8:], uint64(delta))
+	return append(buf, data...)
+}
+
+// decodeStale splits data written by [encodeStale]. It reports false for
+// data without the header, which is then a plain value.
+func decodeStale(data []byte) (staleEntry, bool) {
+	if !bytes.HasPrefix(data, staleHeader) || len(data) < len(staleHeader)+staleHeaderLen {
+		return staleEntry{}, false
+	}
+	meta := data[len(staleHeader):]
+	return staleEntry{
+		softExpiry: time.Unix(0, int64(binary.BigEndian.Uint64(meta))),
+		delta:      time.Duration(binary.BigEndian.Uint64(meta[8:])),
+		data:       meta[staleHeaderLen:],
+	}, true
+}
+
+// needsRefresh reports whether e should be refreshed at now. Past its soft
+// expiry it always should. Before that, with a positive beta, it follows
+// the XFetch algorithm: the entry is refreshed early with a probability
+// that grows as the expiry approaches and with the time the value took to
+// compute, so that refreshes of popular keys spread out instead of piling
+// up at the same instant.
+func (e staleEntry) needsRefresh(now time.Time, beta float64) bool {
+	if !now.Before(e.softExpiry) {
+		return true
+	}
+	if beta <= 0 || e.delta <= 0 {
+		return false
+	}
+	gap := -float64(e.delta) * beta * math.Log(1-rand.Float64())
+	return now.Add(time.Duration(gap)).After(e.softExpiry)
+}
+
+// refresh reloads key in the background unless a load for it is already in
+// flight. The caller keeps serving the value it already has.
+func refresh[T any](ctx context.Context, c Cache, key string, fn func() (T, error), opts *fetchOptions) {
+	g := opts.group
+	if g == nil {
+		g = &refreshes
+	}
+	g.start(ctx, key, opts.flightTTL, func(ctx context.Context) (interface{}, error) {
+		v, err := load(ctx, c, key, fn, opts)
+		if err != nil && opts.onRefreshError != nil {
+			opts.onRefreshError(key, err)
+		}
+		return v, err
+	})
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TagSetter is implemented by caches that can associate tags with an entry.
+// [Fetch] uses it to store entries with the tags set by [WithTags].
+type TagSetter interface {
+	SetWithTags(ctx context.Context, key string, val []byte, ttl time.Duration, tags ...string) error
+}
+
+// WithTags associates tags with the entry that [Fetch] stores, so that it
+// can later be dropped with [Tagged.InvalidateTag]. It has no effect unless
+// the cache implements [TagSetter].
+func WithTags(tags ...string) FetchOption {
+	return func(opts *fetchOptions) {
+		opts.tags = append(opts.tags, tags...)
+	}
+}
+
+// taggedHeader marks an entry stored together with its tag versions.
+var taggedHeader = []byte("\x00cache:tags\x00")
+
+// Tagged wraps a [Cache] with tag-based invalidation.
+//
+// Every tag has a version token stored in the wrapped cache under
+// "tag:<tag>". An entry records the versions of its tags when it is written,
+// and a read whose recorded versions no longer match counts as a miss.
+// [Tagged.InvalidateTag] therefore only replaces one token, however many
+// keys carry the tag, and works across all instances sharing the cache.
+// Invalidated entries are deleted when next read, or expire with their TTL.
+// Tag versions are created like the version of a [Namespace], so the same
+// caveat about caches that do not implement [Adder] applies.
+type Tagged struct {
+	c Cache
+}
+
+var (
+	_ Cache     = (*Tagged)(nil)
+	_ TagSetter = (*Tagged)(nil)
+)
+
+// NewTagged wraps c with tag support.
+func NewTagged(c Cache) *Tagged {
+	return &Tagged{c: c}
+}
+
+// Set stores val under key without tags.
+func (t *Tagged) Set(ctx context.Context, key string, val []byte, ttl time.Duration) error {
+	return t.SetWithTags(ctx, key, val, ttl)
+}
+
+// SetWithTags stores val under key and associates it with tags.
+func (t *Tagged) SetWithTags(ctx context.Context, key string, val []byte, ttl time.Duration, tags ...string) error {
+	versions := make([]string, len(tags))
+	for i, tag := range tags {
+		version, err := t.tagVersion(ctx, tag)
+		if err != nil {
+			return err
+		}
+		versions[i] = version
+	}
+	return t.c.Set(ctx, key, encodeTagged(tags, versions, val), ttl)
+}
+
+// Get returns the value of key, or [ErrCacheMiss] if it is absent or one of
+// its tags was invalidated after it was stored.
+func (t *Tagged) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := t.c.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	tags, versions, val, ok := decodeTagged(data)
+	if !ok {
+		return nil, errors.Wrap(ErrCorruptEntry, "bad tag header")
+	}
+	for i, tag := range tags {
+		current, err := t.c.Get(ctx, tagKey(tag))
+		if err != nil && !errors.Is(err, ErrCacheMiss) {
+			return nil, err
+		}
+		if err != nil || string(current) != versions[i] {
+			_ = t.c.Delete(ctx, key)
+			return nil, errors.WithStack(ErrCacheMiss)
+		}
+	}
+	return val, nil
+}
+
+// Delete removes key.
+func (t *Tagged) Delete(ctx context.Context, key string) error {
+	return t.c.Delete(ctx, key)
+}
+
+// InvalidateTag drops every entry associated with tag.
+func (t *Tagged) InvalidateTag(ctx context.Context, tag string) error {
+	return errors.WithStack(t.c.Set(ctx, tagKey(tag), []byte(newVersion()), 0))
+}
+
+// tagVersion returns the current version of tag, creating it if needed.
+func (t *Tagged) tagVersion(ctx context.Context, tag string) (string, error) {
+	return loadVersion(ctx, t.c, tagKey(tag))
+}
+
+func tagKey(tag string) string {
+	return "tag" + keySeparator + tag
+}
+
+// encodeTagged prefixes val with the header, the number of tags and each
+// tag with its version, all lengths as uvarints.
+func encodeTagged(tags, versions []string, val []byte) []byte {
+	buf := append([]byte{}, taggedHeader...)
+	var tmp [binary.MaxVarintLen64]byte
+	putString := func(s string) {
+		buf = append(buf, tmp[:binary.PutUvarint(tmp[:], uint64(len(s)))]...)
+		buf = append(buf, s...)
+	}
+	buf = append(buf, tmp[:binary.PutUvarint(tmp[:], uint64(len(tags)))]...)
+	for i, tag := range tags {
+		putString(tag)
+		putString(versions[i])
+	}
+	return append(buf, val...)
+}
+
+// decodeTagged splits data written by [encodeTagged]. Data without the
+// header is returned as an untagged value; ok is false only for a truncated
+// header.
+func decodeTagged(data []byte) (tags, versions []string, val []byte, ok bool) {
+	if !bytes.HasPrefix(data, taggedHeader) {
+		return nil, nil, data, true
+	}
+	rest := data[len(taggedHeader):]
+	readString := func() (string, bool) {
+		n, size := binary.Uvarint(rest)
+		if size <= 0 || uint64(len(rest)-size) < n {
+			return "", false
+		}
+		s := string(rest[size : size+int(n)])
+		rest = rest[size+int(n):]
+		return s, true
+	}
+
+	count, size := binary.Uvarint(rest)
+	if size <= 0 || count > uint64(len(rest)) {
+		return nil, nil, nil, false
+	}
+	rest = rest[size:]
+	tags = make([]string, count)
+	versions = make([]string, count)
+	for i := range tags {
+		if tags[i], ok = readString(); !ok {
+			return nil, nil, nil, false
+		}
+		if versions[i], ok = readString(); !ok {
+			return nil, nil, nil, false
+		}
+	}
+	return tags, versions, rest, true
+}
@@ -0,0 +1,270 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Invalidation is a message telling other instances to drop their local
+// copies of Keys. Source identifies the sender so that it can ignore its own
+// messages.
+type Invalidation struct {
+	Source string
+	Keys   []string
+}
+
+// InvalidationBus broadcasts [Invalidation] messages between the instances
+// that share a remote cache, e.g. over Redis pub/sub or a message queue.
+type InvalidationBus interface {
+	// Publish sends msg to every subscriber, including those of the sender.
+	Publish(ctx context.Context, msg Invalidation) error
+	// Subscribe registers handler for incoming messages. The returned
+	// function removes it.
+	Subscribe(handler func(Invalidation)) (cancel func())
+}
+
+// tieredOptions holds the resolved configuration for [NewTiered].
+type tieredOptions struct {
+	localTTL time.Duration
+	bus      InvalidationBus
+}
+
+// TieredOption configures a [Tiered] cache at construction time.
+type TieredOption func(*tieredOptions)
+
+// WithLocalTTL sets how long entries stay in the local tier. On
+// [Tiered.Set] it is capped by the ttl passed in. A local copy filled by
+// [Tiered.Get] always gets the full local TTL, since the remaining TTL of
+// the remote entry is unknown, so it may outlive the remote entry by up to
+// d. The default is 1 minute.
+func WithLocalTTL(d time.Duration) TieredOption {
+	return func(opts *tieredOptions) {
+		opts.localTTL = d
+	}
+}
+
+// WithInvalidationBus makes the cache publish the keys it writes or deletes
+// on bus, and drop its local copies of keys published by other instances.
+// Without a bus, other instances may serve stale local copies until their
+// local TTL expires.
+func WithInvalidationBus(bus InvalidationBus) TieredOption {
+	return func(opts *tieredOptions) {
+		opts.bus = bus
+	}
+}
+
+// Tiered is a two-level [Cache] that keeps a small, short-lived local tier
+// in front of a shared remote one. Reads try the local tier first and fill
+// it from the remote tier on a miss; writes go through to both.
+type Tiered struct {
+	local    Cache
+	remote   Cache
+	localTTL time.Duration
+	bus      InvalidationBus
+	source   string
+
+	mu    sync.Mutex
+	fills map[string]*fill
+
+	closeOnce   sync.Once
+	unsubscribe func()
+}
+
+// fill tracks the local fills of a key in progress in [Tiered.Get].
+type fill struct {
+	n     int  // number of Get calls filling the key
+	stale bool // the key changed since the remote reads started
+}
+
+var _ Cache = (*Tiered)(nil)
+
+// NewTiered layers local over remote. Call [Tiered.Close] when done to stop
+// listening on the invalidation bus.
+func NewTiered(local, remote Cache, opts ...TieredOption) *Tiered {
+	o := tieredOptions{
+		localTTL: time.Minute,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	t := &Tiered{
+		local:       local,
+		remote:      remote,
+		localTTL:    o.localTTL,
+		bus:         o.bus,
+		source:      newSourceID(),
+		fills:       make(map[string]*fill),
+		unsubscribe: func() {},
+	}
+	if t.bus != nil {
+		t.unsubscribe = t.bus.Subscribe(t.invalidate)
+	}
+	return t
+}
+
+// Get returns the local copy of key if there is one, and otherwise reads
+// the remote tier and keeps a local copy of the result. The copy is not
+// kept if key was written, deleted or invalidated during the remote read,
+// as the value read may already be outdated.
+func (t *Tiered) Get(ctx context.Context, key string) ([]byte, error) {
+	if val, err := t.local.Get(ctx, key); err == nil {
+		return val, nil
+	}
+	f := t.beginFill(key)
+	val, err := t.remote.Get(ctx, key)
+	t.endFill(ctx, key, f, val, err == nil)
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+// beginFill registers a local fill of key in progress.
+func (t *Tiered) beginFill(key string) *fill {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f, ok := t.fills[key]
+	if !ok {
+		f = &fill{}
+		t.fills[key] = f
+	}
+	f.n++
+	return f
+}
+
+// endFill unregisters f and, if ok and key did not change meanwhile,
+// stores val in the local tier. The write happens under the lock so that a
+// concurrent [Tiered.markStale] either prevents it or deletes it afterwards.
+func (t *Tiered) endFill(ctx context.Context, key string, f *fill, val []byte, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if f.n--; f.n == 0 {
+		delete(t.fills, key)
+	}
+	if ok && !f.stale {
+		// A failed local write only costs a later remote read.
+		_ = t.local.Set(ctx, key, val, t.localTTL)
+	}
+}
+
+// markStale keeps the fills of key in progress from storing what they read.
+// It must be called before the local copy of key is replaced or dropped.
+func (t *Tiered) markStale(key string) {
+	t.mu.Lock()
+	if f, ok := t.fills[key]; ok {
+		f.stale = true
+	}
+	t.mu.Unlock()
+}
+
+// Set writes val to the remote tier, then to the local one, and tells
+// other instances to drop their local copies.
+func (t *Tiered) Set(ctx context.Context, key string, val []byte, ttl time.Duration) error {
+	if err := t.remote.Set(ctx, key, val, ttl); err != nil {
+		return err
+	}
+	t.markStale(key)
+	localTTL := t.localTTL
+	if ttl > 0 && ttl < localTTL {
+		localTTL = ttl
+	}
+	if err := t.local.Set(ctx, key, val, localTTL); err != nil {
+		_ = t.local.Delete(ctx, key)
+	}
+	return t.publish(ctx, key)
+}
+
+// Delete removes key from both tiers and tells other instances to drop
+// their local copies.
+func (t *Tiered) Delete(ctx context.Context, key string) error {
+	if err := t.remote.Delete(ctx, key); err != nil {
+		return err
+	}
+	t.markStale(key)
+	if err := t.local.Delete(ctx, key); err != nil {
+		return err
+	}
+	return t.publish(ctx, key)
+}
+
+// Close stops listening on the invalidation bus. It does not close either
+// tier. Calling Close more than once is a no-op.
+func (t *Tiered) Close() error {
+	t.closeOnce.Do(t.unsubscribe)
+	return nil
+}
+
+func (t *Tiered) publish(ctx context.Context, keys ...string) error {
+	if t.bus == nil {
+		return nil
+	}
+	err := t.bus.Publish(ctx, Invalidation{Source: t.source, Keys: keys})
+	return errors.Wrap(err, "publish invalidation")
+}
+
+// invalidate drops the local copies of keys written by other instances.
+func (t *Tiered) invalidate(msg Invalidation) {
+	if msg.Source == t.source {
+		return
+	}
+	for _, key := range msg.Keys {
+		t.markStale(key)
+		_ = t.local.Delete(context.Background(), key)
+	}
+}
+
+// newSourceID returns a random identifier for a [Tiered] instance.
+func newSourceID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// LocalBus is an in-process [InvalidationBus] that delivers messages
+// synchronously. It connects several [Tiered] caches in the same process,
+// which is mostly useful in tests.
+type LocalBus struct {
+	mu       sync.RWMutex
+	next     int
+	handlers map[int]func(Invalidation)
+}
+
+var _ InvalidationBus = (*LocalBus)(nil)
+
+// Publish calls every subscribed handler with msg.
+func (b *LocalBus) Publish(_ context.Context, msg Invalidation) error {
+	b.mu.RLock()
+	handlers := make([]func(Invalidation), 0, len(b.handlers))
+	for _, h := range b.handlers {
+		handlers = append(handlers, h)
+	}
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		h(msg)
+	}
+	return nil
+}
+
+// Subscribe registers handler until the returned function is called.
+func (b *LocalBus) Subscribe(handler func(Invalidation)) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.handlers == nil {
+		b.handlers = make(map[int]func(Invalidation))
+	}
+	id := b.next
+	b.next++
+	b.handlers[id] = handler
+	return func() {
+		b.mu.Lock()
+		delete(b.handlers, id)
+		b.mu.Unlock()
+	}
+}
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTiered(t *testing.T) {
+	ctx := context.Background()
+	remote := newMapCache()
+	var bus LocalBus
+	local1, local2 := newMapCache(), newMapCache()
+	t1 := NewTiered(local1, remote, WithInvalidationBus(&bus))
+	defer t1.Close()
+	t2 := NewTiered(local2, remote, WithInvalidationBus(&bus))
+	defer t2.Close()
+
+	// Write-through to both tiers of the writer.
+	require.NoError(t, t1.Set(ctx, "k", []byte("v1"), time.Minute))
+	_, err := local1.Get(ctx, "k")
+	require.NoError(t, err)
+
+	// A remote hit fills the local tier of the reader.
+	val, err := t2.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("v1"), val)
+	_, err = local2.Get(ctx, "k")
+	require.NoError(t, err)
+
+	// An update elsewhere drops the local copy instead of serving it stale.
+	require.NoError(t, t1.Set(ctx, "k", []byte("v2"), time.Minute))
+	val, err = t2.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("v2"), val)
+
+	require.NoError(t, t2.Delete(ctx, "k"))
+	_, err = t1.Get(ctx, "k")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+
+	// After Close, t2 no longer listens.
+	require.NoError(t, t2.Close())
+	require.NoError(t, t2.Set(ctx, "k", []byte("v3"), time.Minute))
+	require.NoError(t, t1.Set(ctx, "k", []byte("v4"), time.Minute))
+	val, err = t2.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("v3"), val)
+}
+
+// hookCache runs onGet before every read.
+type hookCache struct {
+	*mapCache
+	onGet func()
+}
+
+func (c *hookCache) Get(ctx context.Context, key string) ([]byte, error) {
+	val, err := c.mapCache.Get(ctx, key)
+	if c.onGet != nil {
+		c.onGet()
+	}
+	return val, err
+}
+
+func TestTiered_InvalidatedFill(t *testing.T) {
+	ctx := context.Background()
+	remote := &hookCache{mapCache: newMapCache()}
+	var bus LocalBus
+	local := newMapCache()
+	t1 := NewTiered(newMapCache(), remote, WithInvalidationBus(&bus))
+	defer t1.Close()
+	t2 := NewTiered(local, remote, WithInvalidationBus(&bus))
+	defer t2.Close()
+	require.NoError(t, t1.Set(ctx, "k", []byte("v1"), time.Minute))
+
+	// The key changes after t2 read it remotely but before the local fill.
+	remote.onGet = func() {
+		remote.onGet = nil
+		require.NoError(t, t1.Set(ctx, "k", []byte("v2"), time.Minute))
+	}
+	val, err := t2.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("v1"), val)
+	_, err = local.Get(ctx, "k")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+
+	val, err = t2.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("v2"), val)
+}
@@ -291,7 +291,9 @@ type instantiateOptions struct {
 type InstantiateOption func(*instantiateOptions)
 
 // WithExecutor sets the task executor for the instance.
-// The default is [executors.GoExecutor] which runs each node in a new goroutine.
+// By default each run uses the executor carried by its context (see
+// [future.WithExecutorContext]), or [executors.GoExecutor], which runs each
+// node in a new goroutine, if the context carries none.
 func WithExecutor(executor future.Executor) InstantiateOption {
 	return func(opts *instantiateOptions) {
 		opts.executor = executor
@@ -323,9 +325,7 @@ func (d *DAG) Instantiate(input any, options ...InstantiateOption) (*DAGInstance
 		return nil, ErrDAGNotFrozen
 	}
 
-	opts := instantiateOptions{
-		executor: executors.GoExecutor{},
-	}
+	opts := instantiateOptions{}
 	for _, option := range options {
 		option(&opts)
 	}
@@ -440,7 +440,7 @@ type DAGInstance struct {
 	spec  *DAG
 	nodes map[NodeID]*NodeInstance
 
-	executor future.Executor
+	executor future.Executor // nil selects the context executor per run
 }
 
 // Run synchronously executes the DAG and returns all node results.
@@ -476,13 +476,14 @@ func (d *DAGInstance) RunAsync(ctx context.Context) *future.Future[map[NodeID]an
 	)
 }
 
-// runNode submits a node for asynchronous execution. Once the node completes,
+// runNode submits a node for asynchronous execution. Once the node resolves,
 // it decrements the pending count of all children and triggers any child
-// whose dependencies are fully satisfied.
+// whose dependencies are fully satisfied. A node whose context is done
+// before it starts is skipped and resolves with the context's error.
 func (d *DAGInstance) runNode(ctx context.Context, id NodeID) {
 	node := d.nodes[id]
 	node.startTime = time.Now()
-	future.Submit(d.executor, func() (any, error) {
+	future.SubmitContext(ctx, d.executorFor(ctx), func() (any, error) {
 		deps := make(map[NodeID]any, len(node.spec.Deps()))
 		for _, depid := range node.spec.Deps() {
 			v, err := d.nodes[depid].result.Get()
@@ -496,13 +497,24 @@ func (d *DAGInstance) runNode(ctx context.Context, id NodeID) {
 		}
 		val, err := node.run(ctx, deps)
 		node.endTime = time.Now()
+		return val, err
+	}).Subscribe(func(val any, err error) {
 		for _, childID := range node.children {
 			if d.nodes[childID].pending.Add(-1) == 0 {
 				d.runNode(ctx, childID)
 			}
 		}
-		return val, err
-	}).Subscribe(func(val any, err error) {
 		node.promise.Set(val, err)
 	})
 }
+
+// executorFor returns the executor that runs nodes for a run with ctx.
+func (d *DAGInstance) executorFor(ctx context.Context) future.Executor {
+	if d.executor != nil {
+		return d.executor
+	}
+	if e, ok := future.ExecutorFromContext(ctx); ok {
+		return e
+	}
+	return executors.GoExecutor{}
+}
@@ -3,12 +3,15 @@ package dag
 import (
 	"context"
 	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
+
+	"github.com/saltfishpr/pkg/future"
 )
 
 func TestMain(m *testing.M) {
@@ -559,3 +562,25 @@ func TestDAG_SkipNode(t *testing.T) {
 	assert.NotContains(t, results, "node2")
 	assert.NotContains(t, results, "node2-1")
 }
+
+func TestDAGInstance_ContextExecutor(t *testing.T) {
+	d := NewDAG("entry")
+	_ = d.AddNode("node1", []NodeID{"entry"}, func(ctx context.Context, deps map[NodeID]any) (any, error) {
+		return deps["entry"].(int) + 1, nil
+	})
+	_ = d.Freeze()
+
+	var submitted atomic.Int32
+	e := future.ExecutorFunc(func(f func()) {
+		submitted.Add(1)
+		go f()
+	})
+
+	inst, err := d.Instantiate(1)
+	require.NoError(t, err)
+
+	results, err := inst.Run(future.WithExecutorContext(context.Background(), e))
+	require.NoError(t, err)
+	assert.Equal(t, 2, results["node1"].(int))
+	assert.Equal(t, int32(2), submitted.Load())
+}
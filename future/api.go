@@ -3,8 +3,6 @@ package future
 import (
 	"context"
 	"errors"
-	"fmt"
-	"runtime/debug"
 	"sync/atomic"
 	"time"
 
@@ -13,32 +11,83 @@ import (
 
 // Sentinel errors used by the combinators in this package.
 var (
-	ErrPanic   = errors.New("async panic")
-	ErrTimeout = errors.New("future timeout")
+	ErrPanic     = errors.New("async panic")
+	ErrTimeout   = errors.New("future timeout")
+	ErrCancelled = errors.New("future cancelled")
 )
 
 // Async runs f in a new goroutine (using the package-level executor) and
 // returns a [Future] that resolves when f completes. Panics inside f are
-// recovered and surfaced as errors wrapping [ErrPanic].
+// recovered and surfaced as a [*PanicError], which matches [ErrPanic].
 func Async[T any](f func() (T, error)) *Future[T] {
-	return Submit(executor, f)
+	return Submit(loadExecutor(), f)
+}
+
+// AsyncContext is like [Async] but runs f on the [Executor] carried by ctx
+// (see [WithExecutorContext]), falling back to the package-level executor.
+// Like [SubmitContext], it skips f if ctx is done before f starts.
+func AsyncContext[T any](ctx context.Context, f func() (T, error)) *Future[T] {
+	return SubmitContext(ctx, ContextExecutor(ctx), f)
 }
 
 // Submit is like [Async] but uses the provided [Executor].
 func Submit[T any](e Executor, f func() (T, error)) *Future[T] {
-	s := &state[T]{}
+	s := newState[T]()
+	submit(e, s, f)
+	return &Future[T]{state: s}
+}
+
+// SubmitContext is like [Submit] but skips f when ctx is done before f
+// starts, resolving the Future with ctx.Err() instead. Work whose caller
+// has already given up therefore does not occupy the executor. If e
+// implements [ContextSubmitter], the decision is left to e, which may drop
+// such tasks while they are still queued.
+func SubmitContext[T any](ctx context.Context, e Executor, f func() (T, error)) *Future[T] {
+	s := newState[T]()
+	task := func(err error) {
+		if err != nil {
+			var zero T
+			s.set(zero, err)
+			return
+		}
+		run(e, s, f)
+	}
+	if cs, ok := e.(ContextSubmitter); ok {
+		cs.SubmitContext(ctx, task)
+	} else {
+		e.Submit(func() {
+			task(ctx.Err())
+		})
+	}
+	return &Future[T]{state: s}
+}
+
+// submit runs f on e and resolves s with its result.
+func submit[T any](e Executor, s *state[T], f func() (T, error)) {
 	e.Submit(func() {
-		var val T
-		var err error
-		defer func() {
-			if r := recover(); r != nil {
-				err = fmt.Errorf("%w, err=%s, stack=%s", ErrPanic, r, debug.Stack())
-			}
-			s.set(val, err)
-		}()
-		val, err = f()
+		run(e, s, f)
 	})
-	return &Future[T]{state: s}
+}
+
+// run calls f on the current goroutine and resolves s with its result,
+// converting panics into a [*PanicError]. e is the executor running f.
+func run[T any](e Executor, s *state[T], f func() (T, error)) {
+	var val T
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			perr := newPanicError(r)
+			if hook := loadPanicHook(); hook != nil {
+				hook(perr)
+			}
+			if o, ok := e.(PanicObserver); ok {
+				o.ObservePanic(r)
+			}
+			err = perr
+		}
+		s.set(val, err)
+	}()
+	val, err = f()
 }
 
 // Done returns an already-resolved [Future] carrying val with a nil error.
@@ -48,7 +97,7 @@ func Done[T any](val T) *Future[T] {
 
 // Done2 returns an already-resolved [Future] carrying val and err.
 func Done2[T any](val T, err error) *Future[T] {
-	s := &state[T]{}
+	s := newState[T]()
 	s.set(val, err)
 	return &Future[T]{state: s}
 }
@@ -57,7 +106,7 @@ func Done2[T any](val T, err error) *Future[T] {
 // result, and a new [Future] is returned carrying cb's output. This is
 // analogous to Promise.then() in JavaScript.
 func Then[T any, R any](f *Future[T], cb func(T, error) (R, error)) *Future[R] {
-	s := &state[R]{}
+	s := newState[R]()
 	f.state.subscribe(func(val T, err error) {
 		rval, rerr := cb(val, err)
 		s.set(rval, rerr)
@@ -65,6 +114,64 @@ func Then[T any, R any](f *Future[T], cb func(T, error) (R, error)) *Future[R] {
 	return &Future[R]{state: s}
 }
 
+// ThenAsync is like [Then] but dispatches cb to e instead of running it in
+// the goroutine that resolves f, so cb may block without stalling the
+// producer or other subscribers. A nil e selects the package-level executor.
+// Panics inside cb are surfaced as a [*PanicError].
+func ThenAsync[T any, R any](f *Future[T], e Executor, cb func(T, error) (R, error)) *Future[R] {
+	if e == nil {
+		e = loadExecutor()
+	}
+	s := newState[R]()
+	f.state.subscribe(func(val T, err error) {
+		submit(e, s, func() (R, error) {
+			return cb(val, err)
+		})
+	})
+	return &Future[R]{state: s}
+}
+
+// Map transforms the value of f with fn once f resolves successfully. If f
+// fails, fn is skipped and the error is propagated unchanged.
+func Map[T any, R any](f *Future[T], fn func(T) (R, error)) *Future[R] {
+	return Then(f, func(val T, err error) (R, error) {
+		if err != nil {
+			var zero R
+			return zero, err
+		}
+		return fn(val)
+	})
+}
+
+// FlatMap is like [Map] but fn returns a [Future]; the returned Future
+// resolves with the result of the Future produced by fn. If f fails, fn is
+// skipped and the error is propagated unchanged.
+func FlatMap[T any, R any](f *Future[T], fn func(T) *Future[R]) *Future[R] {
+	s := newState[R]()
+	f.state.subscribe(func(val T, err error) {
+		if err != nil {
+			var zero R
+			s.set(zero, err)
+			return
+		}
+		fn(val).state.subscribe(func(rval R, rerr error) {
+			s.set(rval, rerr)
+		})
+	})
+	return &Future[R]{state: s}
+}
+
+// Recover converts a failure of f into a result via fn. If f succeeds, its
+// value is passed through and fn is not called.
+func Recover[T any](f *Future[T], fn func(error) (T, error)) *Future[T] {
+	return Then(f, func(val T, err error) (T, error) {
+		if err != nil {
+			return fn(err)
+		}
+		return val, nil
+	})
+}
+
 // AllOf waits for every Future in fs to resolve and collects their values
 // into a slice. If any Future fails, the returned Future resolves
 // immediately with that error; remaining successes are discarded.
@@ -75,7 +182,7 @@ func AllOf[T any](fs ...*Future[T]) *Future[[]T] {
 	}
 
 	var done uint32
-	s := &state[[]T]{}
+	s := newState[[]T]()
 	c := int32(len(fs))
 	results := make([]T, len(fs))
 	for i, f := range fs {
@@ -100,7 +207,7 @@ func AllOf[T any](fs ...*Future[T]) *Future[[]T] {
 // returned Future resolves with [ErrTimeout].
 func Timeout[T any](f *Future[T], d time.Duration) *Future[T] {
 	var done uint32
-	s := &state[T]{}
+	s := newState[T]()
 	timer := time.AfterFunc(d, func() {
 		if atomic.CompareAndSwapUint32(&done, 0, 1) {
 			var zero T
@@ -121,7 +228,7 @@ func Timeout[T any](f *Future[T], d time.Duration) *Future[T] {
 // context's error.
 func WithContext[T any](ctx context.Context, f *Future[T]) *Future[T] {
 	var done uint32
-	s := &state[T]{}
+	s := newState[T]()
 	routine.GoSafe(func() {
 		select {
 		case <-ctx.Done():
@@ -0,0 +1,200 @@
+package future
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestZip2(t *testing.T) {
+	p1 := NewPromise[int]()
+	p2 := NewPromise[string]()
+	f := Zip2(p1.Future(), p2.Future())
+
+	p2.Set("a", nil)
+	assert.False(t, f.IsDone())
+	p1.Set(1, nil)
+
+	val, err := f.Get()
+	require.NoError(t, err)
+	assert.Equal(t, Tuple2[int, string]{V1: 1, V2: "a"}, val)
+}
+
+func TestZip3_Error(t *testing.T) {
+	wantErr := errors.New("boom")
+	f := Zip3(Done(1), Done2("", wantErr), NewPromise[bool]().Future())
+
+	_, err := f.Get()
+	assert.ErrorIs(t, err, wantErr)
+}
+
+func TestZip5(t *testing.T) {
+	f := Zip5(Async(func() (int, error) { return 1, nil }), Done("b"), Done(true), Done(2.5), Done([]int{5}))
+
+	val, err := f.Get()
+	require.NoError(t, err)
+	assert.Equal(t, 1, val.V1)
+	assert.Equal(t, "b", val.V2)
+	assert.True(t, val.V3)
+	assert.Equal(t, 2.5, val.V4)
+	assert.Equal(t, []int{5}, val.V5)
+}
+
+func TestMap(t *testing.T) {
+	val, err := Map(Done(2), func(v int) (string, error) {
+		return string(rune('a' + v)), nil
+	}).Get()
+	require.NoError(t, err)
+	assert.Equal(t, "c", val)
+
+	wantErr := errors.New("boom")
+	called := false
+	_, err = Map(Done2(0, wantErr), func(v int) (string, error) {
+		called = true
+		return "", nil
+	}).Get()
+	assert.ErrorIs(t, err, wantErr)
+	assert.False(t, called)
+}
+
+func TestFlatMap(t *testing.T) {
+	p := NewPromise[string]()
+	f := FlatMap(Done(1), func(v int) *Future[string] {
+		return p.Future()
+	})
+	assert.False(t, f.IsDone())
+
+	p.Set("x", nil)
+	val, err := f.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "x", val)
+}
+
+func TestRecover(t *testing.T) {
+	val, err := Recover(Done2(0, errors.New("boom")), func(err error) (int, error) {
+		return 42, nil
+	}).Get()
+	require.NoError(t, err)
+	assert.Equal(t, 42, val)
+
+	val, err = Recover(Done(1), func(err error) (int, error) {
+		return 42, nil
+	}).Get()
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+}
+
+func TestThenAsync(t *testing.T) {
+	p := NewPromise[int]()
+	release := make(chan struct{})
+	f := ThenAsync(p.Future(), nil, func(v int, err error) (int, error) {
+		<-release
+		return v * 2, err
+	})
+
+	// Set must not block on the slow continuation.
+	p.Set(21, nil)
+	close(release)
+
+	val, err := f.Get()
+	require.NoError(t, err)
+	assert.Equal(t, 42, val)
+}
+
+func TestThenAsync_Panic(t *testing.T) {
+	_, err := ThenAsync(Done(1), nil, func(int, error) (int, error) {
+		panic("boom")
+	}).Get()
+	assert.ErrorIs(t, err, ErrPanic)
+}
+
+func TestFuture_SubscribeOn(t *testing.T) {
+	var ran []string
+	e := ExecutorFunc(func(f func()) {
+		ran = append(ran, "executor")
+		f()
+	})
+
+	done := make(chan int, 1)
+	Done(7).SubscribeOn(e, func(val int, err error) {
+		done <- val
+	})
+
+	assert.Equal(t, 7, <-done)
+	assert.Equal(t, []string{"executor"}, ran)
+}
+
+func TestFuture_GetContext(t *testing.T) {
+	p := NewPromise[int]()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := p.Future().GetContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	p.Set(1, nil)
+	val, err := p.Future().GetContext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+}
+
+func TestFuture_GetTimeout(t *testing.T) {
+	p := NewPromise[int]()
+
+	_, err := p.Future().GetTimeout(10 * time.Millisecond)
+	assert.ErrorIs(t, err, ErrTimeout)
+
+	p.Set(1, nil)
+	val, err := p.Future().GetTimeout(10 * time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+}
+
+func TestAsyncContext(t *testing.T) {
+	var submitted int
+	e := ExecutorFunc(func(f func()) {
+		submitted++
+		f()
+	})
+	ctx := WithExecutorContext(context.Background(), e)
+
+	got, ok := ExecutorFromContext(ctx)
+	require.True(t, ok)
+	assert.NotNil(t, got)
+
+	val, err := AsyncContext(ctx, func() (int, error) { return 1, nil }).Get()
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+	assert.Equal(t, 1, submitted)
+
+	_, ok = ExecutorFromContext(context.Background())
+	assert.False(t, ok)
+}
+
+func TestSubmitContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ran := false
+	_, err := SubmitContext(ctx, loadExecutor(), func() (int, error) {
+		ran = true
+		return 1, nil
+	}).Get()
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, ran)
+
+	val, err := SubmitContext(context.Background(), loadExecutor(), func() (int, error) {
+		return 2, nil
+	}).Get()
+	require.NoError(t, err)
+	assert.Equal(t, 2, val)
+}
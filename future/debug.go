@@ -0,0 +1,96 @@
+package future
+
+import (
+	"reflect"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	debugEnabled  atomic.Bool
+	debugSeq      atomic.Uint64
+	debugRegistry sync.Map // uint64 -> *debugRecord
+)
+
+// SetDebug turns debug mode on or off. While it is on, every new [Promise]
+// and [Future] records its creation stack and stays listed by [Pending]
+// until it is resolved. Debug mode costs a stack capture per Future and
+// keeps never-resolved Futures reachable, so enable it only in tests or
+// while diagnosing a hang.
+func SetDebug(enabled bool) {
+	debugEnabled.Store(enabled)
+}
+
+// DebugEnabled reports whether debug mode is on.
+func DebugEnabled() bool {
+	return debugEnabled.Load()
+}
+
+// PendingFuture describes an unresolved Future created while debug mode was
+// on.
+type PendingFuture struct {
+	ID      uint64            // unique, increasing in creation order
+	Type    string            // the Future's value type
+	Created time.Time         // creation time
+	Age     time.Duration     // time since creation when Pending was called
+	Stack   errors.StackTrace // creation stack; format with %+v
+}
+
+// Pending returns every Future created in debug mode that has not been
+// resolved yet, oldest first.
+func Pending() []PendingFuture {
+	now := time.Now()
+	var pending []PendingFuture
+	debugRegistry.Range(func(_, value any) bool {
+		rec := value.(*debugRecord)
+		frames := make(errors.StackTrace, len(rec.callers))
+		for i, pc := range rec.callers {
+			frames[i] = errors.Frame(pc)
+		}
+		pending = append(pending, PendingFuture{
+			ID:      rec.id,
+			Type:    rec.typ,
+			Created: rec.created,
+			Age:     now.Sub(rec.created),
+			Stack:   frames,
+		})
+		return true
+	})
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].ID < pending[j].ID
+	})
+	return pending
+}
+
+// debugRecord is the creation site of a state tracked in debug mode.
+type debugRecord struct {
+	id      uint64
+	typ     string
+	created time.Time
+	callers []uintptr
+}
+
+// trackState registers a new state of type T. It must be called directly
+// from newState.
+func trackState[T any]() *debugRecord {
+	var callers [32]uintptr
+	// Skip runtime.Callers, trackState and newState.
+	n := runtime.Callers(3, callers[:])
+	rec := &debugRecord{
+		id:      debugSeq.Add(1),
+		typ:     reflect.TypeOf((*T)(nil)).Elem().String(),
+		created: time.Now(),
+		callers: callers[:n],
+	}
+	debugRegistry.Store(rec.id, rec)
+	return rec
+}
+
+func untrackState(rec *debugRecord) {
+	debugRegistry.Delete(rec.id)
+}
@@ -0,0 +1,23 @@
+package future
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPending(t *testing.T) {
+	SetDebug(true)
+	defer SetDebug(false)
+
+	p := NewPromise[int]()
+	pending := Pending()
+	require.Len(t, pending, 1)
+	assert.Equal(t, "int", pending[0].Type)
+	assert.Contains(t, fmt.Sprintf("%+v", pending[0].Stack), "TestPending")
+
+	p.Set(1, nil)
+	assert.Empty(t, Pending())
+}
@@ -1,13 +1,19 @@
 package future
 
-import "github.com/saltfishpr/pkg/future/executors"
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/saltfishpr/pkg/future/executors"
+)
 
 // Executor abstracts task submission so the scheduling strategy can be
 // replaced without changing call-site code.
 //
 // The default executor is [executors.GoExecutor], which spawns a goroutine
 // per task. Override it with [SetExecutor] to use a bounded worker pool for
-// back-pressure or goroutine reuse.
+// back-pressure or goroutine reuse, or scope an executor to a call tree
+// with [WithExecutorContext].
 //
 // Caution: a pool-based executor may cause tasks to queue under load,
 // especially for blocking RPC calls. Only override after profiling confirms
@@ -16,6 +22,25 @@ type Executor interface {
 	Submit(func())
 }
 
+// ContextSubmitter may be implemented by an [Executor] that can skip tasks
+// whose context is done before they start. [SubmitContext] and the dag
+// engine use it when available.
+//
+// The executor must call task exactly once: with nil when it starts the
+// task, or, instead of starting it, with ctx.Err() once ctx is done. The
+// latter lets the caller resolve its Future without the task ever running.
+type ContextSubmitter interface {
+	SubmitContext(ctx context.Context, task func(err error))
+}
+
+// PanicObserver may be implemented by an [Executor] that wants to learn
+// about panics. The tasks this package submits recover their own panics, so
+// the executor never sees them unwinding; instead, ObservePanic is called
+// with the panic value on the task's goroutine before the Future resolves.
+type PanicObserver interface {
+	ObservePanic(value any)
+}
+
 // ExecutorFunc is an adapter that lets an ordinary function satisfy the
 // [Executor] interface.
 type ExecutorFunc func(func())
@@ -25,14 +50,59 @@ func (e ExecutorFunc) Submit(f func()) {
 	e(f)
 }
 
+// executorHolder gives every value stored in [executor] the same concrete
+// type, as [atomic.Value] requires.
+type executorHolder struct {
+	e Executor
+}
+
 // executor is the package-level default used by [Async].
-var executor Executor = executors.GoExecutor{}
+var executor atomic.Value // executorHolder
+
+func init() {
+	executor.Store(executorHolder{e: executors.GoExecutor{}})
+}
+
+// loadExecutor returns the package-level executor.
+func loadExecutor() Executor {
+	return executor.Load().(executorHolder).e
+}
 
-// SetExecutor replaces the package-level executor used by [Async].
-// Passing nil panics.
+// SetExecutor replaces the package-level executor used by [Async]. It is
+// safe to call concurrently with running tasks. Passing nil panics.
 func SetExecutor(e Executor) {
 	if e == nil {
 		panic("executor is nil")
 	}
-	executor = e
+	executor.Store(executorHolder{e: e})
+}
+
+// executorKey is the context key under which [WithExecutorContext] stores
+// an [Executor].
+type executorKey struct{}
+
+// WithExecutorContext returns a copy of ctx carrying e. Context-aware
+// helpers such as [AsyncContext], [Hedge] and [Stream] use it instead of the
+// package-level executor. Passing nil panics.
+func WithExecutorContext(ctx context.Context, e Executor) context.Context {
+	if e == nil {
+		panic("executor is nil")
+	}
+	return context.WithValue(ctx, executorKey{}, e)
+}
+
+// ExecutorFromContext returns the [Executor] stored in ctx by
+// [WithExecutorContext], if any.
+func ExecutorFromContext(ctx context.Context) (Executor, bool) {
+	e, ok := ctx.Value(executorKey{}).(Executor)
+	return e, ok
+}
+
+// ContextExecutor returns the [Executor] stored in ctx, falling back to the
+// package-level executor.
+func ContextExecutor(ctx context.Context) Executor {
+	if e, ok := ExecutorFromContext(ctx); ok {
+		return e
+	}
+	return loadExecutor()
 }
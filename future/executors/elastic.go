@@ -0,0 +1,253 @@
+package executors
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// elasticPoolOptions holds the resolved configuration for [NewElasticPool].
+type elasticPoolOptions struct {
+	idleTimeout time.Duration
+}
+
+// ElasticPoolOption configures an [ElasticPool] at construction time.
+type ElasticPoolOption func(*elasticPoolOptions)
+
+// WithIdleTimeout sets how long a worker above the core count may stay idle
+// before it exits. The default is 1 minute.
+func WithIdleTimeout(d time.Duration) ElasticPoolOption {
+	return func(opts *elasticPoolOptions) {
+		opts.idleTimeout = d
+	}
+}
+
+// ElasticPool is a worker pool that grows with demand and shrinks when
+// idle. It sits between [GoExecutor], which never reuses goroutines, and
+// [WorkerPool], whose size is fixed.
+//
+// A submitted task is handed to an idle worker if there is one; otherwise a
+// new worker is started, up to the maximum. Once the maximum is reached,
+// [ElasticPool.Submit] blocks until a worker frees up. Workers are started
+// on demand, and those above the core count exit after staying idle for the
+// idle timeout.
+//
+// Tasks must not panic; [future.Submit] already recovers panics inside the
+// functions it runs.
+type ElasticPool struct {
+	core        int
+	max         int
+	idleTimeout time.Duration
+	tasks       chan func()   // unbuffered; idle workers receive from it
+	done        chan struct{} // closed on shutdown to release blocked senders
+	wg          sync.WaitGroup
+
+	mu       sync.RWMutex // guards shutdown and senders.Add
+	shutdown bool
+	senders  sync.WaitGroup // callers between the shutdown check and the send on tasks
+
+	sizeMu  sync.Mutex // guards workers and waiting
+	workers int
+	waiting int // callers blocked in Submit
+
+	active    atomic.Int64
+	completed atomic.Uint64
+	rejected  atomic.Uint64
+}
+
+// NewElasticPool creates an [ElasticPool] that keeps up to core workers
+// alive and runs at most max tasks at a time. It panics unless
+// 0 <= core <= max and max > 0.
+func NewElasticPool(core, max int, opts ...ElasticPoolOption) *ElasticPool {
+	if max <= 0 || core < 0 || core > max {
+		panic("executors: invalid elastic pool size")
+	}
+	o := elasticPoolOptions{
+		idleTimeout: time.Minute,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &ElasticPool{
+		core:        core,
+		max:         max,
+		idleTimeout: o.idleTimeout,
+		tasks:       make(chan func()),
+		done:        make(chan struct{}),
+	}
+}
+
+// Submit runs f on an idle worker, or on a new one if fewer than max are
+// running, and otherwise blocks until a worker is free. After shutdown, f
+// runs in the caller so that no Future is left unresolved.
+func (p *ElasticPool) Submit(f func()) {
+	if err := p.send(context.Background(), f); err != nil {
+		p.rejected.Add(1)
+		f()
+	}
+}
+
+// SubmitContext is like [ElasticPool.Submit], but skips task if ctx is
+// done by the time a worker picks it up, and stops waiting for a free
+// worker once ctx is done. After shutdown, task is called with
+// [ErrShutdown]. It implements [future.ContextSubmitter].
+func (p *ElasticPool) SubmitContext(ctx context.Context, task func(err error)) {
+	if err := p.send(ctx, contextTask(ctx, task)); err != nil {
+		p.rejected.Add(1)
+		task(err)
+	}
+}
+
+// send hands f to a worker, starting one if the pool is below max. At max
+// it waits for a free worker until the pool shuts down or ctx is done,
+// returning [ErrShutdown] or ctx.Err(). No lock is held while waiting, so a
+// shutdown is never held up by a blocked sender.
+func (p *ElasticPool) send(ctx context.Context, f func()) error {
+	p.mu.RLock()
+	if p.shutdown {
+		p.mu.RUnlock()
+		return ErrShutdown
+	}
+	p.senders.Add(1)
+	p.mu.RUnlock()
+	defer p.senders.Done()
+
+	select {
+	case p.tasks <- f:
+		return nil
+	default:
+	}
+
+	p.sizeMu.Lock()
+	if p.workers < p.max {
+		p.workers++
+		p.sizeMu.Unlock()
+		p.wg.Add(1)
+		go p.worker(f)
+		return nil
+	}
+	p.waiting++
+	p.sizeMu.Unlock()
+	defer func() {
+		p.sizeMu.Lock()
+		p.waiting--
+		p.sizeMu.Unlock()
+	}()
+
+	select {
+	case p.tasks <- f:
+		return nil
+	case <-p.done:
+		return ErrShutdown
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// worker runs f and then keeps taking tasks until the pool shuts down or
+// the worker is retired after staying idle.
+func (p *ElasticPool) worker(f func()) {
+	defer p.wg.Done()
+
+	timer := time.NewTimer(p.idleTimeout)
+	defer timer.Stop()
+	for f != nil {
+		p.active.Add(1)
+		f()
+		p.active.Add(-1)
+		p.completed.Add(1)
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(p.idleTimeout)
+		f = p.next(timer)
+	}
+}
+
+// next waits for the next task. It returns nil when the worker should exit.
+func (p *ElasticPool) next(timer *time.Timer) func() {
+	for {
+		select {
+		case f, ok := <-p.tasks:
+			if !ok {
+				p.sizeMu.Lock()
+				p.workers--
+				p.sizeMu.Unlock()
+				return nil
+			}
+			return f
+		case <-timer.C:
+			if p.reap() {
+				return nil
+			}
+			timer.Reset(p.idleTimeout)
+		}
+	}
+}
+
+// reap retires an idle worker if the pool is above its core size and no
+// caller is waiting for a worker.
+func (p *ElasticPool) reap() bool {
+	p.sizeMu.Lock()
+	defer p.sizeMu.Unlock()
+	if p.workers <= p.core || p.waiting > 0 {
+		return false
+	}
+	p.workers--
+	return true
+}
+
+// Shutdown stops accepting new tasks and waits for running ones to finish.
+// If ctx is done first, it returns ctx.Err() while the workers finish in
+// the background.
+func (p *ElasticPool) Shutdown(ctx context.Context) error {
+	p.close()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close marks the pool as shut down and closes tasks exactly once, after the
+// senders that got past the shutdown check have left.
+func (p *ElasticPool) close() {
+	p.mu.Lock()
+	if p.shutdown {
+		p.mu.Unlock()
+		return
+	}
+	p.shutdown = true
+	close(p.done)
+	p.mu.Unlock()
+
+	p.senders.Wait()
+	close(p.tasks)
+}
+
+// Stats returns a snapshot of the pool's counters. Queued counts callers
+// blocked in [ElasticPool.Submit] waiting for a free worker.
+func (p *ElasticPool) Stats() PoolStats {
+	p.sizeMu.Lock()
+	workers, waiting := p.workers, p.waiting
+	p.sizeMu.Unlock()
+	return PoolStats{
+		Workers:   workers,
+		Active:    int(p.active.Load()),
+		Queued:    waiting,
+		Completed: p.completed.Load(),
+		Rejected:  p.rejected.Load(),
+	}
+}
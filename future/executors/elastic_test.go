@@ -0,0 +1,104 @@
+package executors
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElasticPool_GrowAndShrink(t *testing.T) {
+	p := NewElasticPool(1, 4, WithIdleTimeout(20*time.Millisecond))
+
+	release := make(chan struct{})
+	var started sync.WaitGroup
+	started.Add(4)
+	for i := 0; i < 4; i++ {
+		p.Submit(func() {
+			started.Done()
+			<-release
+		})
+	}
+	started.Wait()
+	assert.Equal(t, 4, p.Stats().Workers)
+	assert.Equal(t, 4, p.Stats().Active)
+
+	// A fifth task blocks until a worker frees up.
+	submitted := make(chan struct{})
+	go func() {
+		p.Submit(func() {})
+		close(submitted)
+	}()
+	select {
+	case <-submitted:
+		t.Fatal("Submit should block at max workers")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(release)
+	<-submitted
+
+	// Extra workers retire after the idle timeout, down to the core size.
+	assert.Eventually(t, func() bool {
+		return p.Stats().Workers == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, uint64(5), p.Stats().Completed)
+
+	require.NoError(t, p.Shutdown(context.Background()))
+	assert.Equal(t, 0, p.Stats().Workers)
+}
+
+func TestElasticPool_Reuse(t *testing.T) {
+	p := NewElasticPool(0, 2)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		p.Submit(wg.Done)
+		wg.Wait()
+	}
+	assert.LessOrEqual(t, p.Stats().Workers, 2)
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	ran := false
+	p.Submit(func() { ran = true })
+	assert.True(t, ran)
+	assert.Equal(t, uint64(1), p.Stats().Rejected)
+}
+
+func TestElasticPool_SubmitContext(t *testing.T) {
+	p := NewElasticPool(0, 1)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	p.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	// At max workers, waiting for a free worker stops once ctx is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	errc := make(chan error, 1)
+	p.SubmitContext(ctx, func(err error) { errc <- err })
+	assert.ErrorIs(t, <-errc, context.DeadlineExceeded)
+
+	// A blocked Submit does not hold up Shutdown.
+	submitted := make(chan struct{})
+	go func() {
+		p.Submit(func() {})
+		close(submitted)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	sctx, scancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer scancel()
+	assert.ErrorIs(t, p.Shutdown(sctx), context.DeadlineExceeded)
+	<-submitted
+
+	p.SubmitContext(context.Background(), func(err error) { errc <- err })
+	assert.ErrorIs(t, <-errc, ErrShutdown)
+
+	close(release)
+	require.NoError(t, p.Shutdown(context.Background()))
+}
@@ -1,6 +1,24 @@
 // Package executors provides built-in [future.Executor] implementations.
+//
+//   - [GoExecutor]: a new goroutine per task; the default.
+//   - [PoolExecutor]: a goroutine per task, capped by a semaphore.
+//   - [WorkerPool]: a fixed set of workers fed by a bounded queue.
+//   - [ElasticPool]: workers that grow with demand and retire when idle.
+//   - [SerialExecutor] / [KeyedExecutor]: strict ordering, per executor or
+//     per key.
+//   - [PriorityExecutor]: a fixed set of workers serving a priority queue
+//     with aging.
+//   - [InstrumentedExecutor]: timing, counters and hooks around any executor.
 package executors
 
+import "context"
+
+// Executor mirrors [future.Executor], which this package cannot import.
+// Every future.Executor satisfies it and vice versa.
+type Executor interface {
+	Submit(func())
+}
+
 // GoExecutor spawns a new goroutine for every submitted task.
 // It is the default executor used by the future package.
 type GoExecutor struct{}
@@ -12,7 +30,8 @@ func (GoExecutor) Submit(f func()) {
 
 // PoolExecutor limits concurrency to a fixed number of workers using a
 // semaphore channel. Each submitted task acquires a slot before running
-// and releases it on completion.
+// and releases it on completion. Use [WorkerPool] for goroutine reuse, a
+// bounded queue and graceful shutdown.
 type PoolExecutor struct {
 	sem chan struct{}
 }
@@ -34,3 +53,27 @@ func (p *PoolExecutor) Submit(f func()) {
 		f()
 	}()
 }
+
+// SubmitContext is like [PoolExecutor.Submit], but gives up waiting for a
+// slot once ctx is done, and skips task if ctx is done by the time it
+// starts. It implements [future.ContextSubmitter].
+func (p *PoolExecutor) SubmitContext(ctx context.Context, task func(err error)) {
+	select {
+	case p.sem <- struct{}{}:
+	case <-ctx.Done():
+		task(ctx.Err())
+		return
+	}
+	go func() {
+		defer func() { <-p.sem }()
+		task(ctx.Err())
+	}()
+}
+
+// contextTask adapts a [future.ContextSubmitter] task to a plain task that
+// is skipped if ctx is done by the time it starts.
+func contextTask(ctx context.Context, task func(err error)) func() {
+	return func() {
+		task(ctx.Err())
+	}
+}
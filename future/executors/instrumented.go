@@ -0,0 +1,202 @@
+package executors
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// TaskInfo describes a finished task observed by an [InstrumentedExecutor].
+type TaskInfo struct {
+	QueueWait time.Duration // from Submit until the task started
+	RunTime   time.Duration // from start until the task returned or panicked
+	Panicked  bool          // the task itself panicked
+}
+
+// ExecutorStats is a point-in-time snapshot of an [InstrumentedExecutor].
+type ExecutorStats struct {
+	Submitted    uint64        // tasks passed to Submit
+	Completed    uint64        // tasks that finished running
+	Panics       uint64        // panics raised by tasks or reported via ObservePanic
+	Queued       int64         // tasks submitted but not started
+	Running      int64         // tasks started but not finished
+	QueueWait    time.Duration // total queue wait of completed tasks
+	RunTime      time.Duration // total run time of completed tasks
+	MaxQueueWait time.Duration // longest queue wait seen
+	MaxRunTime   time.Duration // longest run time seen
+}
+
+// instrumentOptions holds the resolved configuration for [Instrument].
+type instrumentOptions struct {
+	before  func(wait time.Duration)
+	after   func(info TaskInfo)
+	onPanic func(value any)
+}
+
+// InstrumentOption configures an [InstrumentedExecutor].
+type InstrumentOption func(*instrumentOptions)
+
+// WithBeforeHook registers fn to run on the task's goroutine right before
+// each task starts, with the time the task spent queued.
+func WithBeforeHook(fn func(wait time.Duration)) InstrumentOption {
+	return func(opts *instrumentOptions) {
+		opts.before = fn
+	}
+}
+
+// WithAfterHook registers fn to run on the task's goroutine right after
+// each task finishes, including when it panics.
+func WithAfterHook(fn func(info TaskInfo)) InstrumentOption {
+	return func(opts *instrumentOptions) {
+		opts.after = fn
+	}
+}
+
+// WithPanicHook registers fn to be called with the value of every panic
+// counted in [ExecutorStats.Panics].
+func WithPanicHook(fn func(value any)) InstrumentOption {
+	return func(opts *instrumentOptions) {
+		opts.onPanic = fn
+	}
+}
+
+// InstrumentedExecutor decorates another [Executor] to measure how long
+// tasks wait before starting and how long they run, and to count panics.
+// Queue wait is the best signal of saturation: it grows when the wrapped
+// executor cannot keep up.
+//
+// A panicking task is counted and then re-panics, so the wrapped executor
+// behaves as before. The future package recovers panics inside the tasks
+// it submits before they reach the executor; it reports them through
+// [InstrumentedExecutor.ObservePanic] instead, so they are counted too.
+type InstrumentedExecutor struct {
+	next Executor
+	opts instrumentOptions
+
+	submitted    atomic.Uint64
+	completed    atomic.Uint64
+	panics       atomic.Uint64
+	queued       atomic.Int64
+	running      atomic.Int64
+	queueWait    atomic.Int64
+	runTime      atomic.Int64
+	maxQueueWait atomic.Int64
+	maxRunTime   atomic.Int64
+}
+
+// Instrument wraps next in an [InstrumentedExecutor].
+func Instrument(next Executor, opts ...InstrumentOption) *InstrumentedExecutor {
+	e := &InstrumentedExecutor{next: next}
+	for _, opt := range opts {
+		opt(&e.opts)
+	}
+	return e
+}
+
+// Submit records f and hands a timed wrapper of it to the wrapped executor.
+func (e *InstrumentedExecutor) Submit(f func()) {
+	e.submitted.Add(1)
+	e.queued.Add(1)
+	submitted := time.Now()
+	e.next.Submit(func() {
+		e.run(submitted, f)
+	})
+}
+
+// run times and counts f, which was submitted at the given time.
+func (e *InstrumentedExecutor) run(submitted time.Time, f func()) {
+	started := time.Now()
+	wait := started.Sub(submitted)
+	e.queued.Add(-1)
+	e.running.Add(1)
+	if e.opts.before != nil {
+		e.opts.before(wait)
+	}
+
+	defer func() {
+		r := recover()
+		run := time.Since(started)
+		e.running.Add(-1)
+		e.queueWait.Add(int64(wait))
+		e.runTime.Add(int64(run))
+		storeMax(&e.maxQueueWait, int64(wait))
+		storeMax(&e.maxRunTime, int64(run))
+		if r != nil {
+			e.ObservePanic(r)
+		}
+		if e.opts.after != nil {
+			e.opts.after(TaskInfo{QueueWait: wait, RunTime: run, Panicked: r != nil})
+		}
+		e.completed.Add(1)
+		if r != nil {
+			panic(r)
+		}
+	}()
+	f()
+}
+
+// SubmitContext is like [InstrumentedExecutor.Submit] for a
+// [future.ContextSubmitter] task. The task is skipped if ctx is done by the
+// time it starts; the wrapped executor decides when that is if it
+// implements SubmitContext itself. Skipped tasks are counted as completed
+// but not timed.
+func (e *InstrumentedExecutor) SubmitContext(ctx context.Context, task func(err error)) {
+	next, ok := e.next.(interface {
+		SubmitContext(ctx context.Context, task func(err error))
+	})
+	if !ok {
+		e.Submit(contextTask(ctx, task))
+		return
+	}
+
+	e.submitted.Add(1)
+	e.queued.Add(1)
+	submitted := time.Now()
+	next.SubmitContext(ctx, func(err error) {
+		if err != nil {
+			e.queued.Add(-1)
+			task(err)
+			e.completed.Add(1)
+			return
+		}
+		e.run(submitted, func() { task(nil) })
+	})
+}
+
+// ObservePanic counts a panic recovered from one of the tasks and passes it
+// on to the wrapped executor if that one observes panics too. The future
+// package calls it for every panic it recovers from a task.
+func (e *InstrumentedExecutor) ObservePanic(value any) {
+	e.panics.Add(1)
+	if e.opts.onPanic != nil {
+		e.opts.onPanic(value)
+	}
+	if o, ok := e.next.(interface{ ObservePanic(value any) }); ok {
+		o.ObservePanic(value)
+	}
+}
+
+// Stats returns a snapshot of the executor's counters.
+func (e *InstrumentedExecutor) Stats() ExecutorStats {
+	return ExecutorStats{
+		Submitted:    e.submitted.Load(),
+		Completed:    e.completed.Load(),
+		Panics:       e.panics.Load(),
+		Queued:       e.queued.Load(),
+		Running:      e.running.Load(),
+		QueueWait:    time.Duration(e.queueWait.Load()),
+		RunTime:      time.Duration(e.runTime.Load()),
+		MaxQueueWait: time.Duration(e.maxQueueWait.Load()),
+		MaxRunTime:   time.Duration(e.maxRunTime.Load()),
+	}
+}
+
+// storeMax raises v to n if n is larger.
+func storeMax(v *atomic.Int64, n int64) {
+	for {
+		old := v.Load()
+		if n <= old || v.CompareAndSwap(old, n) {
+			return
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package executors_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/saltfishpr/pkg/future"
+	"github.com/saltfishpr/pkg/future/executors"
+)
+
+func TestInstrumentedExecutor(t *testing.T) {
+	var mu sync.Mutex
+	var infos []executors.TaskInfo
+	var panics []any
+	e := executors.Instrument(executors.GoExecutor{},
+		executors.WithAfterHook(func(info executors.TaskInfo) {
+			mu.Lock()
+			infos = append(infos, info)
+			mu.Unlock()
+		}),
+		executors.WithPanicHook(func(value any) {
+			mu.Lock()
+			panics = append(panics, value)
+			mu.Unlock()
+		}),
+	)
+
+	_, err := future.Submit(e, func() (int, error) {
+		time.Sleep(5 * time.Millisecond)
+		return 1, nil
+	}).Get()
+	require.NoError(t, err)
+
+	_, err = future.Submit(e, func() (int, error) {
+		panic("boom")
+	}).Get()
+	assert.ErrorIs(t, err, future.ErrPanic)
+
+	assert.Eventually(t, func() bool {
+		return e.Stats().Completed == 2
+	}, time.Second, time.Millisecond)
+	stats := e.Stats()
+	assert.Equal(t, uint64(2), stats.Submitted)
+	assert.Equal(t, uint64(1), stats.Panics)
+	assert.Zero(t, stats.Queued)
+	assert.Zero(t, stats.Running)
+	assert.GreaterOrEqual(t, stats.MaxRunTime, 5*time.Millisecond)
+	assert.GreaterOrEqual(t, stats.RunTime, stats.MaxRunTime)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, infos, 2)
+	assert.Equal(t, []any{"boom"}, panics)
+}
+
+func TestInstrumentedExecutor_RawPanic(t *testing.T) {
+	e := executors.Instrument(future.ExecutorFunc(func(f func()) { f() }))
+	assert.PanicsWithValue(t, "boom", func() {
+		e.Submit(func() { panic("boom") })
+	})
+	assert.Equal(t, uint64(1), e.Stats().Panics)
+	assert.Equal(t, uint64(1), e.Stats().Completed)
+}
@@ -0,0 +1,148 @@
+package executors
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"sync"
+
+	"github.com/saltfishpr/pkg/consisthash"
+)
+
+// SerialExecutor runs submitted tasks one at a time in submission order.
+//
+// It starts a goroutine when the first task arrives and lets it exit once
+// the queue is empty, so an idle SerialExecutor holds no goroutine and
+// needs no shutdown. The queue is unbounded; Submit never blocks.
+type SerialExecutor struct {
+	mu      sync.Mutex
+	queue   []func()
+	running bool
+}
+
+// Submit appends f to the queue. It runs after every task submitted before
+// it has returned.
+func (s *SerialExecutor) Submit(f func()) {
+	s.mu.Lock()
+	s.queue = append(s.queue, f)
+	if s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = true
+	s.mu.Unlock()
+
+	go s.drain()
+}
+
+// SubmitContext is like [SerialExecutor.Submit], but skips task if ctx is
+// done by the time its turn comes. It implements [future.ContextSubmitter].
+func (s *SerialExecutor) SubmitContext(ctx context.Context, task func(err error)) {
+	s.Submit(contextTask(ctx, task))
+}
+
+// Len returns the number of tasks waiting to run.
+func (s *SerialExecutor) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queue)
+}
+
+func (s *SerialExecutor) drain() {
+	for {
+		s.mu.Lock()
+		if len(s.queue) == 0 {
+			s.running = false
+			s.queue = nil
+			s.mu.Unlock()
+			return
+		}
+		f := s.queue[0]
+		s.queue[0] = nil
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+
+		f()
+	}
+}
+
+// keyedExecutorOptions holds the resolved configuration for
+// [NewKeyedExecutor].
+type keyedExecutorOptions struct {
+	replicas int
+}
+
+// KeyedExecutorOption configures a [KeyedExecutor] at construction time.
+type KeyedExecutorOption func(*keyedExecutorOptions)
+
+// WithConsistentHashing assigns keys to lanes with a [consisthash.Ring]
+// using the given number of virtual nodes per lane, instead of the default
+// FNV-1a hash modulo the lane count.
+func WithConsistentHashing(replicas int) KeyedExecutorOption {
+	return func(opts *keyedExecutorOptions) {
+		opts.replicas = replicas
+	}
+}
+
+// KeyedExecutor guarantees that tasks submitted with the same key run
+// strictly in submission order, while tasks for different keys may run in
+// parallel.
+//
+// Keys are hashed onto a fixed number of serial lanes, so unrelated keys
+// that share a lane also run one after another; more lanes mean less
+// head-of-line blocking. Use [KeyedExecutor.For] to obtain a
+// [future.Executor] for a key:
+//
+//	k := executors.NewKeyedExecutor(16)
+//	f := future.Submit(k.For(orderID), func() (Order, error) { ... })
+type KeyedExecutor struct {
+	lanes []*SerialExecutor
+	ring  *consisthash.Ring[int]
+}
+
+// NewKeyedExecutor creates a [KeyedExecutor] with the given number of
+// lanes. It panics if lanes is not positive.
+func NewKeyedExecutor(lanes int, opts ...KeyedExecutorOption) *KeyedExecutor {
+	if lanes <= 0 {
+		panic("executors: lanes must be positive")
+	}
+	o := keyedExecutorOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	k := &KeyedExecutor{
+		lanes: make([]*SerialExecutor, lanes),
+	}
+	ids := make([]int, lanes)
+	for i := range k.lanes {
+		k.lanes[i] = &SerialExecutor{}
+		ids[i] = i
+	}
+	if o.replicas > 0 {
+		k.ring = consisthash.NewRing(o.replicas, strconv.Itoa)
+		k.ring.Add(ids...)
+	}
+	return k
+}
+
+// For returns the serial lane that runs every task for key. The result
+// satisfies [future.Executor].
+func (k *KeyedExecutor) For(key string) *SerialExecutor {
+	return k.lanes[k.lane(key)]
+}
+
+// Submit runs f after every task previously submitted for key.
+func (k *KeyedExecutor) Submit(key string, f func()) {
+	k.For(key).Submit(f)
+}
+
+func (k *KeyedExecutor) lane(key string) int {
+	if k.ring != nil {
+		idx, _ := k.ring.Get(key)
+		return idx
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum64() % uint64(len(k.lanes)))
+}
@@ -0,0 +1,51 @@
+package executors
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyedExecutor_Order(t *testing.T) {
+	for name, k := range map[string]*KeyedExecutor{
+		"fnv":         NewKeyedExecutor(4),
+		"consisthash": NewKeyedExecutor(4, WithConsistentHashing(50)),
+	} {
+		k := k
+		t.Run(name, func(t *testing.T) {
+			var mu sync.Mutex
+			got := make(map[string][]int)
+			var wg sync.WaitGroup
+			for i := 0; i < 100; i++ {
+				for _, key := range []string{"a", "b", "c", "d", "e"} {
+					key, i := key, i
+					wg.Add(1)
+					k.Submit(key, func() {
+						defer wg.Done()
+						mu.Lock()
+						got[key] = append(got[key], i)
+						mu.Unlock()
+					})
+				}
+			}
+			wg.Wait()
+
+			for key, seq := range got {
+				assert.Len(t, seq, 100, key)
+				for i, v := range seq {
+					assert.Equal(t, i, v, key)
+				}
+			}
+		})
+	}
+}
+
+func TestKeyedExecutor_For(t *testing.T) {
+	k := NewKeyedExecutor(8)
+	for i := 0; i < 20; i++ {
+		key := strconv.Itoa(i)
+		assert.Same(t, k.For(key), k.For(key))
+	}
+}
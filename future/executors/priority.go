@@ -0,0 +1,212 @@
+package executors
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// priorityExecutorOptions holds the resolved configuration for
+// [NewPriorityExecutor].
+type priorityExecutorOptions struct {
+	aging time.Duration
+}
+
+// PriorityExecutorOption configures a [PriorityExecutor] at construction
+// time.
+type PriorityExecutorOption func(*priorityExecutorOptions)
+
+// WithAging sets how long a task must wait to gain one priority level, so
+// that a steady stream of high-priority work cannot starve lower-priority
+// tasks forever. The default is 1 second; 0 disables aging and serves
+// strictly by priority, then in submission order.
+func WithAging(d time.Duration) PriorityExecutorOption {
+	return func(opts *priorityExecutorOptions) {
+		opts.aging = d
+	}
+}
+
+// PriorityExecutor runs tasks on a fixed set of workers, always picking the
+// queued task with the highest effective priority. The effective priority
+// of a task is its submitted priority plus one level per aging interval it
+// has waited.
+//
+// [PriorityExecutor.Submit] uses priority 0. Use
+// [PriorityExecutor.SubmitPriority], or pass [PriorityExecutor.WithPriority]
+// wherever a [future.Executor] is accepted:
+//
+//	p := executors.NewPriorityExecutor(8)
+//	future.Submit(p.WithPriority(10), handleRequest)
+//	future.Submit(p.WithPriority(-10), refreshCache)
+//
+// The queue is unbounded. Tasks must not panic; [future.Submit] already
+// recovers panics inside the functions it runs.
+type PriorityExecutor struct {
+	aging time.Duration
+	wg    sync.WaitGroup
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	queue    priorityQueue
+	seq      uint64
+	shutdown bool
+}
+
+// NewPriorityExecutor starts a [PriorityExecutor] with the given number of
+// workers. It panics if workers is not positive.
+func NewPriorityExecutor(workers int, opts ...PriorityExecutorOption) *PriorityExecutor {
+	if workers <= 0 {
+		panic("executors: workers must be positive")
+	}
+	o := priorityExecutorOptions{
+		aging: time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	p := &PriorityExecutor{
+		aging: o.aging,
+	}
+	p.cond = sync.NewCond(&p.mu)
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.worker()
+	}
+	return p
+}
+
+// Submit queues f with priority 0.
+func (p *PriorityExecutor) Submit(f func()) {
+	p.SubmitPriority(0, f)
+}
+
+// SubmitPriority queues f with the given priority; larger values run first.
+// After shutdown, f runs in the caller so that no Future is left
+// unresolved.
+func (p *PriorityExecutor) SubmitPriority(priority int, f func()) {
+	p.mu.Lock()
+	if p.shutdown {
+		p.mu.Unlock()
+		f()
+		return
+	}
+	// Comparing priority + waited/aging between two tasks at any instant is
+	// the same as comparing enqueued - priority*aging, so the heap key never
+	// has to be updated as tasks age.
+	key := -int64(priority)
+	if p.aging > 0 {
+		key = time.Now().UnixNano() - int64(priority)*int64(p.aging)
+	}
+	p.seq++
+	heap.Push(&p.queue, &priorityTask{key: key, seq: p.seq, f: f})
+	p.mu.Unlock()
+	p.cond.Signal()
+}
+
+// SubmitContext is like [PriorityExecutor.Submit], but skips task if ctx is
+// done by the time a worker picks it up. It implements
+// [future.ContextSubmitter].
+func (p *PriorityExecutor) SubmitContext(ctx context.Context, task func(err error)) {
+	p.Submit(contextTask(ctx, task))
+}
+
+// WithPriority returns a view of p whose Submit uses the given priority.
+// The result satisfies [future.Executor].
+func (p *PriorityExecutor) WithPriority(priority int) Executor {
+	return priorityView{p: p, priority: priority}
+}
+
+// Len returns the number of queued tasks.
+func (p *PriorityExecutor) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.queue.Len()
+}
+
+func (p *PriorityExecutor) worker() {
+	defer p.wg.Done()
+	for {
+		p.mu.Lock()
+		for p.queue.Len() == 0 && !p.shutdown {
+			p.cond.Wait()
+		}
+		if p.queue.Len() == 0 {
+			p.mu.Unlock()
+			return
+		}
+		t := heap.Pop(&p.queue).(*priorityTask)
+		p.mu.Unlock()
+
+		t.f()
+	}
+}
+
+// Shutdown stops accepting new tasks and waits for the queued and running
+// ones to finish. If ctx is done first, it returns ctx.Err() while the
+// workers keep draining in the background.
+func (p *PriorityExecutor) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	p.shutdown = true
+	p.mu.Unlock()
+	p.cond.Broadcast()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// priorityView submits to a [PriorityExecutor] with a fixed priority.
+type priorityView struct {
+	p        *PriorityExecutor
+	priority int
+}
+
+func (v priorityView) Submit(f func()) {
+	v.p.SubmitPriority(v.priority, f)
+}
+
+func (v priorityView) SubmitContext(ctx context.Context, task func(err error)) {
+	v.p.SubmitPriority(v.priority, contextTask(ctx, task))
+}
+
+// priorityTask is a queued task of a [PriorityExecutor].
+type priorityTask struct {
+	key int64  // smaller runs first
+	seq uint64 // breaks ties in submission order
+	f   func()
+}
+
+// priorityQueue implements [heap.Interface] over queued tasks.
+type priorityQueue []*priorityTask
+
+func (q priorityQueue) Len() int { return len(q) }
+
+func (q priorityQueue) Less(i, j int) bool {
+	if q[i].key != q[j].key {
+		return q[i].key < q[j].key
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q priorityQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *priorityQueue) Push(x any) { *q = append(*q, x.(*priorityTask)) }
+
+func (q *priorityQueue) Pop() any {
+	old := *q
+	n := len(old)
+	t := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return t
+}
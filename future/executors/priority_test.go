@@ -0,0 +1,61 @@
+package executors
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriorityExecutor(t *testing.T) {
+	p := NewPriorityExecutor(1, WithAging(0))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	p.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	var mu sync.Mutex
+	var order []int
+	for _, prio := range []int{1, 5, -3, 5, 10} {
+		prio := prio
+		p.WithPriority(prio).Submit(func() {
+			mu.Lock()
+			order = append(order, prio)
+			mu.Unlock()
+		})
+	}
+	assert.Equal(t, 5, p.Len())
+	close(release)
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	assert.Equal(t, []int{10, 5, 5, 1, -3}, order)
+}
+
+func TestPriorityExecutor_Aging(t *testing.T) {
+	p := NewPriorityExecutor(1, WithAging(time.Millisecond))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	p.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	var order []string
+	p.SubmitPriority(0, func() { order = append(order, "old") })
+	time.Sleep(20 * time.Millisecond)
+	p.SubmitPriority(5, func() { order = append(order, "new") })
+	close(release)
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	// The low-priority task waited 20 levels' worth, outranking the newcomer.
+	assert.Equal(t, []string{"old", "new"}, order)
+}
@@ -0,0 +1,276 @@
+package executors
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// Errors reported by [WorkerPool] when a task cannot be accepted.
+var (
+	ErrRejected = errors.New("executor rejected task")
+	ErrShutdown = errors.New("executor is shut down")
+)
+
+// RejectPolicy decides what [WorkerPool.Submit] does with a task when the
+// queue is full.
+type RejectPolicy int
+
+const (
+	// RejectBlock waits until the queue has room. It is the default.
+	RejectBlock RejectPolicy = iota
+	// RejectCallerRuns runs the task synchronously in the submitting
+	// goroutine, which naturally slows down the producer.
+	RejectCallerRuns
+	// RejectDiscard drops the task. A Future waiting on it never resolves,
+	// so only use it for fire-and-forget work. [WorkerPool.SubmitContext]
+	// reports the rejection to the task instead.
+	RejectDiscard
+	// RejectAbort panics with [ErrRejected]. Use [WorkerPool.TrySubmit] to
+	// receive the error as a value instead; [WorkerPool.SubmitContext]
+	// never panics.
+	RejectAbort
+)
+
+// PoolStats is a point-in-time snapshot of a [WorkerPool].
+type PoolStats struct {
+	Workers   int    // number of worker goroutines
+	Active    int    // tasks currently running
+	Queued    int    // tasks waiting in the queue
+	Completed uint64 // tasks finished since creation
+	Rejected  uint64 // tasks refused because the queue was full or the pool shut down
+}
+
+// workerPoolOptions holds the resolved configuration for [NewWorkerPool].
+type workerPoolOptions struct {
+	queueSize int
+	policy    RejectPolicy
+}
+
+// WorkerPoolOption configures a [WorkerPool] at construction time.
+type WorkerPoolOption func(*workerPoolOptions)
+
+// WithQueueSize sets the number of tasks that may wait for a free worker.
+// The default is 0: a task is only accepted when a worker is idle.
+func WithQueueSize(n int) WorkerPoolOption {
+	return func(opts *workerPoolOptions) {
+		opts.queueSize = n
+	}
+}
+
+// WithRejectPolicy sets what happens to a task submitted while the queue is
+// full. The default is [RejectBlock].
+func WithRejectPolicy(policy RejectPolicy) WorkerPoolOption {
+	return func(opts *workerPoolOptions) {
+		opts.policy = policy
+	}
+}
+
+// WorkerPool runs tasks on a fixed set of long-lived worker goroutines fed
+// by a bounded queue. Unlike [PoolExecutor], it reuses goroutines and lets
+// callers choose what happens when the queue is full.
+//
+// Tasks must not panic; [future.Submit] already recovers panics inside the
+// functions it runs.
+type WorkerPool struct {
+	workers int
+	policy  RejectPolicy
+	queue   chan func()
+	done    chan struct{} // closed on shutdown to release blocked senders
+	wg      sync.WaitGroup
+
+	mu       sync.RWMutex // guards shutdown and senders.Add
+	shutdown bool
+	senders  sync.WaitGroup // callers between the shutdown check and the send on queue
+
+	active    atomic.Int64
+	completed atomic.Uint64
+	rejected  atomic.Uint64
+}
+
+// NewWorkerPool starts a [WorkerPool] with the given number of workers.
+// It panics if workers is not positive.
+func NewWorkerPool(workers int, opts ...WorkerPoolOption) *WorkerPool {
+	if workers <= 0 {
+		panic("executors: workers must be positive")
+	}
+	o := workerPoolOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	p := &WorkerPool{
+		workers: workers,
+		policy:  o.policy,
+		queue:   make(chan func(), o.queueSize),
+		done:    make(chan struct{}),
+	}
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.worker()
+	}
+	return p
+}
+
+func (p *WorkerPool) worker() {
+	defer p.wg.Done()
+	for f := range p.queue {
+		p.active.Add(1)
+		f()
+		p.active.Add(-1)
+		p.completed.Add(1)
+	}
+}
+
+// Submit queues f for execution. When the queue is full the pool's
+// [RejectPolicy] applies. After shutdown, [RejectAbort] panics with
+// [ErrShutdown], [RejectDiscard] drops f, and the other policies run f in
+// the caller so that no Future is left unresolved.
+func (p *WorkerPool) Submit(f func()) {
+	if err := p.send(context.Background(), f, p.policy == RejectBlock); err != nil {
+		p.reject(f, err)
+	}
+}
+
+// SubmitContext is like [WorkerPool.Submit], but skips task if ctx is done
+// by the time a worker picks it up. With [RejectBlock], it also stops
+// waiting for queue space once ctx is done. It implements
+// [future.ContextSubmitter], so task is always called exactly once: a task
+// that cannot be queued is called with [ErrRejected], [ErrShutdown] or
+// ctx.Err() rather than dropped or turned into a panic, whatever the
+// [RejectPolicy]. Only [RejectCallerRuns] still runs a task that finds the
+// queue full in the caller.
+func (p *WorkerPool) SubmitContext(ctx context.Context, task func(err error)) {
+	f := contextTask(ctx, task)
+	err := p.send(ctx, f, p.policy == RejectBlock)
+	if err == nil {
+		return
+	}
+	p.rejected.Add(1)
+	if errors.Is(err, ErrRejected) && p.policy == RejectCallerRuns {
+		f()
+		return
+	}
+	task(err)
+}
+
+// TrySubmit queues f without blocking. It returns [ErrRejected] if the
+// queue is full or [ErrShutdown] if the pool has been shut down, regardless
+// of the configured [RejectPolicy].
+func (p *WorkerPool) TrySubmit(f func()) error {
+	if err := p.send(context.Background(), f, false); err != nil {
+		p.rejected.Add(1)
+		return err
+	}
+	return nil
+}
+
+// send puts f on the queue. Without block it fails with [ErrRejected] when
+// the queue is full; with block it waits for room until the pool shuts down
+// or ctx is done, returning [ErrShutdown] or ctx.Err(). No lock is held
+// while waiting, so a shutdown is never held up by a blocked sender.
+func (p *WorkerPool) send(ctx context.Context, f func(), block bool) error {
+	p.mu.RLock()
+	if p.shutdown {
+		p.mu.RUnlock()
+		return ErrShutdown
+	}
+	p.senders.Add(1)
+	p.mu.RUnlock()
+	defer p.senders.Done()
+
+	if !block {
+		select {
+		case p.queue <- f:
+			return nil
+		default:
+			return ErrRejected
+		}
+	}
+	select {
+	case p.queue <- f:
+		return nil
+	case <-p.done:
+		return ErrShutdown
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *WorkerPool) reject(f func(), err error) {
+	p.rejected.Add(1)
+	switch p.policy {
+	case RejectDiscard:
+	case RejectAbort:
+		panic(err)
+	default:
+		f()
+	}
+}
+
+// Shutdown stops accepting new tasks and waits for the queued and running
+// ones to finish. If ctx is done first, it returns ctx.Err() while the
+// workers keep draining in the background.
+func (p *WorkerPool) Shutdown(ctx context.Context) error {
+	p.close()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ShutdownNow stops accepting new tasks and removes every queued task that
+// has not started yet, returning them to the caller. Running tasks are not
+// interrupted.
+func (p *WorkerPool) ShutdownNow() []func() {
+	p.close()
+
+	var pending []func()
+	for {
+		select {
+		case f, ok := <-p.queue:
+			if !ok {
+				return pending
+			}
+			pending = append(pending, f)
+		default:
+			return pending
+		}
+	}
+}
+
+// close marks the pool as shut down and closes the queue exactly once, after
+// the senders that got past the shutdown check have left.
+func (p *WorkerPool) close() {
+	p.mu.Lock()
+	if p.shutdown {
+		p.mu.Unlock()
+		return
+	}
+	p.shutdown = true
+	close(p.done)
+	p.mu.Unlock()
+
+	p.senders.Wait()
+	close(p.queue)
+}
+
+// Stats returns a snapshot of the pool's counters.
+func (p *WorkerPool) Stats() PoolStats {
+	return PoolStats{
+		Workers:   p.workers,
+		Active:    int(p.active.Load()),
+		Queued:    len(p.queue),
+		Completed: p.completed.Load(),
+		Rejected:  p.rejected.Load(),
+	}
+}
@@ -0,0 +1,198 @@
+package executors
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestWorkerPool(t *testing.T) {
+	p := NewWorkerPool(4, WithQueueSize(16))
+
+	var mu sync.Mutex
+	var sum int
+	for i := 1; i <= 100; i++ {
+		i := i
+		p.Submit(func() {
+			mu.Lock()
+			sum += i
+			mu.Unlock()
+		})
+	}
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	assert.Equal(t, 5050, sum)
+	stats := p.Stats()
+	assert.Equal(t, 4, stats.Workers)
+	assert.Equal(t, uint64(100), stats.Completed)
+	assert.Zero(t, stats.Rejected)
+}
+
+// blockWorkers occupies every worker of p until the returned func is called.
+func blockWorkers(p *WorkerPool, n int) func() {
+	release := make(chan struct{})
+	var started sync.WaitGroup
+	started.Add(n)
+	for i := 0; i < n; i++ {
+		task := func() {
+			started.Done()
+			<-release
+		}
+		// Retry until an idle worker takes the task, whatever the policy.
+		for p.TrySubmit(task) != nil {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	started.Wait()
+	return func() { close(release) }
+}
+
+func TestWorkerPool_RejectPolicies(t *testing.T) {
+	t.Run("caller runs", func(t *testing.T) {
+		p := NewWorkerPool(1, WithRejectPolicy(RejectCallerRuns))
+		release := blockWorkers(p, 1)
+		rejected := p.Stats().Rejected
+		ran := false
+		p.Submit(func() { ran = true })
+		assert.True(t, ran)
+		release()
+		require.NoError(t, p.Shutdown(context.Background()))
+		assert.Equal(t, rejected+1, p.Stats().Rejected)
+	})
+
+	t.Run("discard", func(t *testing.T) {
+		p := NewWorkerPool(1, WithRejectPolicy(RejectDiscard))
+		release := blockWorkers(p, 1)
+		p.Submit(func() { t.Error("discarded task ran") })
+		release()
+		require.NoError(t, p.Shutdown(context.Background()))
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		p := NewWorkerPool(1, WithRejectPolicy(RejectAbort))
+		release := blockWorkers(p, 1)
+		assert.PanicsWithValue(t, ErrRejected, func() { p.Submit(func() {}) })
+		assert.ErrorIs(t, p.TrySubmit(func() {}), ErrRejected)
+		release()
+		require.NoError(t, p.Shutdown(context.Background()))
+		assert.ErrorIs(t, p.TrySubmit(func() {}), ErrShutdown)
+	})
+}
+
+func TestWorkerPool_ShutdownTimeout(t *testing.T) {
+	p := NewWorkerPool(1)
+	release := blockWorkers(p, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, p.Shutdown(ctx), context.DeadlineExceeded)
+
+	release()
+	require.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestWorkerPool_ShutdownNow(t *testing.T) {
+	p := NewWorkerPool(1, WithQueueSize(3))
+	release := blockWorkers(p, 1)
+	for i := 0; i < 3; i++ {
+		p.Submit(func() {})
+	}
+	assert.Equal(t, 3, p.Stats().Queued)
+
+	pending := p.ShutdownNow()
+	assert.Len(t, pending, 3)
+
+	release()
+	require.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestWorkerPool_SubmitContext(t *testing.T) {
+	p := NewWorkerPool(1, WithQueueSize(1))
+	release := blockWorkers(p, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 2)
+	p.SubmitContext(ctx, func(err error) { errc <- err })
+	cancel()
+	// The queue is full and ctx is done, so this one is rejected right away.
+	p.SubmitContext(ctx, func(err error) { errc <- err })
+	assert.ErrorIs(t, <-errc, context.Canceled)
+
+	release()
+	// The queued task is skipped once a worker picks it up.
+	assert.ErrorIs(t, <-errc, context.Canceled)
+	require.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestWorkerPool_ShutdownWithBlockedSubmitter(t *testing.T) {
+	p := NewWorkerPool(1)
+	unblock := make(chan struct{})
+	resubmitted := make(chan struct{})
+	started := make(chan struct{})
+	p.Submit(func() {
+		close(started)
+		<-unblock
+		// Submitting from a running task must not deadlock with Shutdown.
+		p.Submit(func() { close(resubmitted) })
+	})
+	<-started
+
+	submitted := make(chan struct{})
+	go func() {
+		p.Submit(func() {})
+		close(submitted)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.ErrorIs(t, p.Shutdown(ctx), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	<-submitted
+
+	close(unblock)
+	<-resubmitted
+	require.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestWorkerPool_SubmitContextPolicies(t *testing.T) {
+	for name, policy := range map[string]RejectPolicy{
+		"block":       RejectBlock,
+		"caller runs": RejectCallerRuns,
+		"discard":     RejectDiscard,
+		"abort":       RejectAbort,
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := NewWorkerPool(1, WithRejectPolicy(policy))
+			release := blockWorkers(p, 1)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			errc := make(chan error, 1)
+			p.SubmitContext(ctx, func(err error) { errc <- err })
+			switch policy {
+			case RejectBlock:
+				assert.ErrorIs(t, <-errc, context.DeadlineExceeded)
+			case RejectCallerRuns:
+				assert.NoError(t, <-errc)
+			default:
+				assert.ErrorIs(t, <-errc, ErrRejected)
+			}
+
+			release()
+			require.NoError(t, p.Shutdown(context.Background()))
+			p.SubmitContext(context.Background(), func(err error) { errc <- err })
+			assert.ErrorIs(t, <-errc, ErrShutdown)
+		})
+	}
+}
@@ -8,14 +8,33 @@
 // Key combinators:
 //   - [Async] / [Submit]: run a function asynchronously and return a Future.
 //   - [Then]: chain a transformation on a Future's result.
+//   - [ThenAsync] / [Future.SubscribeOn]: like Then and Subscribe, but run
+//     the continuation on an [Executor].
+//   - [Map] / [FlatMap] / [Recover]: typed shortcuts over Then for the
+//     success and failure paths.
 //   - [AllOf]: fan-in, waiting for all Futures to complete.
+//   - [Zip2] ... [Zip5]: fan-in over Futures of different types.
 //   - [Timeout]: race a Future against a deadline.
 //   - [WithContext]: race a Future against context cancellation.
+//   - [Delay] / [Schedule] / [Every]: run functions later or periodically on
+//     a shared timer wheel.
+//   - [Hedge]: fire delayed speculative attempts and keep the first success.
+//   - [Stream] / [StreamSlice] / [MapSlice]: process many items with bounded
+//     concurrency, ordered or unordered output, and back-pressure.
 //
 // The default executor spawns a goroutine per task ([executors.GoExecutor]).
-// Use [SetExecutor] to substitute a pooled executor for back-pressure control.
+// Use [SetExecutor] to substitute a pooled executor for back-pressure control,
+// or [WithExecutorContext] to select one for a single call tree.
+//
+// To track down a Future that never resolves, enable [SetDebug] and inspect
+// [Pending], or use the futuretest package in tests.
 package future
 
+import (
+	"context"
+	"time"
+)
+
 // Promise is the write-once, producer side of the Promise-Future pair.
 //
 // Call [Promise.Set] exactly once to resolve the associated [Future]. Setting
@@ -29,7 +48,7 @@ type Promise[T any] struct {
 // state. Obtain the read side via [Promise.Future].
 func NewPromise[T any]() *Promise[T] {
 	return &Promise[T]{
-		state: &state[T]{},
+		state: newState[T](),
 	}
 }
 
@@ -62,7 +81,8 @@ func (p *Promise[T]) IsFree() bool {
 // [Future.Get] blocks until the result is available. [Future.Subscribe]
 // registers a non-blocking callback that fires once the result is ready;
 // the callback runs in the goroutine that resolves the Promise, so it must
-// not perform blocking operations.
+// not perform blocking operations. Use [Future.SubscribeOn] for callbacks
+// that may block.
 type Future[T any] struct {
 	state *state[T]
 }
@@ -72,6 +92,19 @@ func (f *Future[T]) Get() (T, error) {
 	return f.state.get()
 }
 
+// GetContext is like [Future.Get] but returns early with ctx.Err() if ctx
+// is done first. Unlike [WithContext], it does not allocate a derived Future
+// or start a goroutine.
+func (f *Future[T]) GetContext(ctx context.Context) (T, error) {
+	return f.state.getContext(ctx)
+}
+
+// GetTimeout is like [Future.Get] but returns early with [ErrTimeout] if the
+// Future is not resolved within d.
+func (f *Future[T]) GetTimeout(d time.Duration) (T, error) {
+	return f.state.getTimeout(d)
+}
+
 // Subscribe registers cb to be called when the Future resolves.
 //
 // The callback executes in the same goroutine that calls [Promise.Set], so
@@ -80,6 +113,19 @@ func (f *Future[T]) Subscribe(cb func(val T, err error)) {
 	f.state.subscribe(cb)
 }
 
+// SubscribeOn is like [Future.Subscribe] but dispatches cb to e, so cb may
+// block. A nil e selects the package-level executor.
+func (f *Future[T]) SubscribeOn(e Executor, cb func(val T, err error)) {
+	if e == nil {
+		e = loadExecutor()
+	}
+	f.state.subscribe(func(val T, err error) {
+		e.Submit(func() {
+			cb(val, err)
+		})
+	})
+}
+
 // IsDone reports whether the Future has been resolved.
 func (f *Future[T]) IsDone() bool {
 	return f.state.isDone()
@@ -0,0 +1,97 @@
+// Package futuretest provides test helpers for code built on the future
+// package.
+//
+// [VerifyResolved] fails a test that leaves a [future.Promise] unresolved,
+// in the same spirit as go.uber.org/goleak for goroutines:
+//
+//	func TestSomething(t *testing.T) {
+//	    futuretest.VerifyResolved(t)
+//	    ...
+//	}
+package futuretest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/saltfishpr/pkg/future"
+)
+
+// TestingT is the subset of [testing.TB] used by [VerifyResolved].
+type TestingT interface {
+	Helper()
+	Cleanup(func())
+	Errorf(format string, args ...any)
+}
+
+// options holds the resolved configuration for [VerifyResolved].
+type options struct {
+	timeout time.Duration
+}
+
+// Option configures the behavior of [VerifyResolved].
+type Option func(*options)
+
+// WithTimeout sets how long [VerifyResolved] waits for outstanding Futures
+// to resolve at the end of the test. The default is 1 second.
+func WithTimeout(d time.Duration) Option {
+	return func(opts *options) {
+		opts.timeout = d
+	}
+}
+
+// VerifyResolved turns on future debug mode for the rest of the test and,
+// when the test finishes, reports an error for every Future created in the
+// meantime that is still unresolved, together with its creation stack.
+//
+// Futures still pending when VerifyResolved is called are ignored. Since
+// debug mode is process-wide, do not combine it with t.Parallel.
+func VerifyResolved(t TestingT, opts ...Option) {
+	t.Helper()
+
+	o := options{
+		timeout: time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ignore := make(map[uint64]struct{})
+	for _, p := range future.Pending() {
+		ignore[p.ID] = struct{}{}
+	}
+	prev := future.DebugEnabled()
+	future.SetDebug(true)
+
+	t.Cleanup(func() {
+		t.Helper()
+		defer future.SetDebug(prev)
+
+		deadline := time.Now().Add(o.timeout)
+		for {
+			var leaked []future.PendingFuture
+			for _, p := range future.Pending() {
+				if _, ok := ignore[p.ID]; !ok {
+					leaked = append(leaked, p)
+				}
+			}
+			if len(leaked) == 0 {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Errorf("found %d unresolved futures:\n%s", len(leaked), format(leaked))
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+}
+
+func format(pending []future.PendingFuture) string {
+	var b strings.Builder
+	for _, p := range pending {
+		_, _ = fmt.Fprintf(&b, "future #%d of %s created %s ago%+v\n\n", p.ID, p.Type, p.Age, p.Stack)
+	}
+	return b.String()
+}
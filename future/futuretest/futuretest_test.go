@@ -0,0 +1,58 @@
+package futuretest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/saltfishpr/pkg/future"
+)
+
+type fakeT struct {
+	cleanups []func()
+	errors   []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Cleanup(f func()) { t.cleanups = append(t.cleanups, f) }
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeT) finish() {
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+}
+
+func TestVerifyResolved(t *testing.T) {
+	ft := &fakeT{}
+	VerifyResolved(ft)
+	p := future.NewPromise[int]()
+	future.Async(func() (int, error) { return 1, nil })
+	p.Set(1, nil)
+	ft.finish()
+
+	assert.Empty(t, ft.errors)
+	assert.False(t, future.DebugEnabled())
+}
+
+func TestVerifyResolved_Leak(t *testing.T) {
+	ft := &fakeT{}
+	VerifyResolved(ft, WithTimeout(20*time.Millisecond))
+	p := future.NewPromise[string]()
+	ft.finish()
+
+	if assert.Len(t, ft.errors, 1) {
+		assert.Contains(t, ft.errors[0], "found 1 unresolved futures")
+		assert.Contains(t, ft.errors[0], "of string")
+		assert.Contains(t, ft.errors[0], "TestVerifyResolved_Leak")
+	}
+
+	// Resolve it so later tests do not see it.
+	p.Set("", nil)
+}
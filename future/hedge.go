@@ -0,0 +1,175 @@
+package future
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// HedgeStats describes how a [Hedge] call played out.
+type HedgeStats struct {
+	Attempts int // attempts started, including the first
+	Hedges   int // speculative attempts fired after the first
+	Winner   int // index of the attempt whose success was returned, or -1
+}
+
+// hedgeOptions holds the resolved configuration for a [Hedge] call.
+type hedgeOptions struct {
+	executor  Executor
+	maxHedges int
+	onStats   func(HedgeStats)
+}
+
+// HedgeOption configures the behavior of [Hedge].
+type HedgeOption func(*hedgeOptions)
+
+// WithHedgeExecutor sets the executor that runs every attempt. The default
+// is the executor carried by the context (see [ContextExecutor]).
+func WithHedgeExecutor(e Executor) HedgeOption {
+	return func(opts *hedgeOptions) {
+		opts.executor = e
+	}
+}
+
+// WithMaxHedges sets how many speculative attempts may be fired in addition
+// to the first one. The default is 1.
+func WithMaxHedges(n int) HedgeOption {
+	return func(opts *hedgeOptions) {
+		opts.maxHedges = n
+	}
+}
+
+// WithHedgeStats registers a callback that receives the [HedgeStats] once
+// the outcome is known, just before the returned Future resolves.
+func WithHedgeStats(fn func(HedgeStats)) HedgeOption {
+	return func(opts *hedgeOptions) {
+		opts.onStats = fn
+	}
+}
+
+// Hedge starts f and, each time delay elapses without a success, fires
+// another speculative attempt until the hedge budget set by [WithMaxHedges]
+// is spent. The returned Future resolves with the first success; the context
+// passed to every attempt is then cancelled so the losers can stop early.
+//
+// A failed attempt does not resolve the Future while other attempts are in
+// flight. If every attempt has failed and hedges remain, the next one is
+// fired immediately; otherwise the Future resolves with the last error.
+// Panics inside f are surfaced as errors wrapping [ErrPanic], as with
+// [Submit]. Combine with [Timeout] to bound the overall wait.
+func Hedge[T any](ctx context.Context, delay time.Duration, f func(ctx context.Context) (T, error), options ...HedgeOption) *Future[T] {
+	opts := hedgeOptions{
+		executor:  ContextExecutor(ctx),
+		maxHedges: 1,
+	}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	h := &hedger[T]{
+		ctx:    ctx,
+		cancel: cancel,
+		delay:  delay,
+		f:      f,
+		opts:   opts,
+		s:      newState[T](),
+		stats:  HedgeStats{Winner: -1},
+	}
+	h.mu.Lock()
+	idx := h.next()
+	h.mu.Unlock()
+	h.launch(idx)
+	return &Future[T]{state: h.s}
+}
+
+// hedger tracks the attempts of a single [Hedge] call.
+type hedger[T any] struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	delay  time.Duration
+	f      func(ctx context.Context) (T, error)
+	opts   hedgeOptions
+	s      *state[T]
+
+	mu       sync.Mutex
+	timer    *time.Timer
+	inflight int
+	done     bool
+	stats    HedgeStats
+}
+
+// next records a new attempt and arms the timer for the following hedge.
+// It must be called with mu held and returns the attempt index.
+func (h *hedger[T]) next() int {
+	idx := h.stats.Attempts
+	h.stats.Attempts++
+	if idx > 0 {
+		h.stats.Hedges++
+	}
+	h.inflight++
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+	}
+	if h.stats.Hedges < h.opts.maxHedges {
+		h.timer = time.AfterFunc(h.delay, h.onTimer)
+	}
+	return idx
+}
+
+// launch submits attempt idx. It must be called without mu held, because
+// the executor may run the attempt synchronously.
+func (h *hedger[T]) launch(idx int) {
+	SubmitContext(h.ctx, h.opts.executor, func() (T, error) {
+		return h.f(h.ctx)
+	}).state.subscribe(func(val T, err error) {
+		h.onResult(idx, val, err)
+	})
+}
+
+func (h *hedger[T]) onTimer() {
+	h.mu.Lock()
+	if h.done || h.ctx.Err() != nil || h.stats.Hedges >= h.opts.maxHedges {
+		h.mu.Unlock()
+		return
+	}
+	idx := h.next()
+	h.mu.Unlock()
+	h.launch(idx)
+}
+
+func (h *hedger[T]) onResult(idx int, val T, err error) {
+	h.mu.Lock()
+	h.inflight--
+	if h.done {
+		h.mu.Unlock()
+		return
+	}
+	if err != nil && h.inflight > 0 {
+		h.mu.Unlock()
+		return
+	}
+	if err != nil && h.stats.Hedges < h.opts.maxHedges && h.ctx.Err() == nil {
+		idx := h.next()
+		h.mu.Unlock()
+		h.launch(idx)
+		return
+	}
+
+	h.done = true
+	if h.timer != nil {
+		h.timer.Stop()
+	}
+	if err == nil {
+		h.stats.Winner = idx
+	}
+	stats := h.stats
+	h.mu.Unlock()
+
+	h.cancel()
+	if h.opts.onStats != nil {
+		h.opts.onStats(stats)
+	}
+	h.s.set(val, err)
+}
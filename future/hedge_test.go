@@ -0,0 +1,68 @@
+package future
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHedge_FirstAttemptWins(t *testing.T) {
+	var stats HedgeStats
+	val, err := Hedge(context.Background(), time.Second, func(ctx context.Context) (int, error) {
+		return 1, nil
+	}, WithHedgeStats(func(s HedgeStats) { stats = s })).Get()
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+	assert.Equal(t, HedgeStats{Attempts: 1, Hedges: 0, Winner: 0}, stats)
+}
+
+func TestHedge_SlowAttemptIsHedged(t *testing.T) {
+	var calls int32
+	statsCh := make(chan HedgeStats, 1)
+	val, err := Hedge(context.Background(), 10*time.Millisecond, func(ctx context.Context) (int32, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if n == 1 {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return n, nil
+	}, WithMaxHedges(2), WithHedgeStats(func(s HedgeStats) { statsCh <- s })).Get()
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), val)
+	assert.Equal(t, HedgeStats{Attempts: 2, Hedges: 1, Winner: 1}, <-statsCh)
+}
+
+func TestHedge_AllFail(t *testing.T) {
+	var calls int32
+	wantErr := errors.New("boom")
+	statsCh := make(chan HedgeStats, 1)
+	_, err := Hedge(context.Background(), time.Second, func(ctx context.Context) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		return 0, wantErr
+	}, WithMaxHedges(2), WithHedgeStats(func(s HedgeStats) { statsCh <- s })).Get()
+	assert.ErrorIs(t, err, wantErr)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Equal(t, HedgeStats{Attempts: 3, Hedges: 2, Winner: -1}, <-statsCh)
+}
+
+func TestHedge_Panic(t *testing.T) {
+	_, err := Hedge(context.Background(), time.Second, func(ctx context.Context) (int, error) {
+		panic("boom")
+	}, WithMaxHedges(0)).Get()
+	assert.ErrorIs(t, err, ErrPanic)
+}
+
+func TestHedge_Timeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err := Timeout(Hedge(ctx, 5*time.Millisecond, func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}), 20*time.Millisecond).Get()
+	assert.ErrorIs(t, err, ErrTimeout)
+}
@@ -0,0 +1,96 @@
+package future
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+
+	"github.com/saltfishpr/pkg/routine"
+)
+
+// PanicError is the error produced when a task run by this package panics.
+// It matches [ErrPanic] with [errors.Is], exposes the original panic value
+// through the embedded [routine.Recovered], and can be extracted as a
+// *[routine.RecoveredError] with [errors.As].
+type PanicError struct {
+	*routine.RecoveredError
+}
+
+// newPanicError captures the stack of a recovered panic. It must be called
+// directly from the deferred function that called recover.
+func newPanicError(value any) *PanicError {
+	// Skip newPanicError and the deferred function so the trace starts at
+	// the panic.
+	recovered := routine.NewRecovered(3, value)
+	return &PanicError{RecoveredError: recovered.AsError().(*routine.RecoveredError)}
+}
+
+// Error returns the panic value prefixed with the [ErrPanic] message. Use
+// %+v to include the stack trace.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrPanic, e.Value)
+}
+
+// Is reports whether target is [ErrPanic].
+func (e *PanicError) Is(target error) bool {
+	return target == ErrPanic
+}
+
+// As assigns the underlying [routine.RecoveredError] when target is a
+// **routine.RecoveredError.
+func (e *PanicError) As(target any) bool {
+	if t, ok := target.(**routine.RecoveredError); ok {
+		*t = e.RecoveredError
+		return true
+	}
+	return false
+}
+
+// StackTrace returns the stack at the panic site. The result is a
+// [errors.StackTrace], which bizerrors.StackTrace aliases.
+func (e *PanicError) StackTrace() errors.StackTrace {
+	return e.RecoveredError.StackTrace()
+}
+
+// Format implements [fmt.Formatter]. The %+v verb appends the stack trace.
+func (e *PanicError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			_, _ = io.WriteString(s, e.Error())
+			e.StackTrace().Format(s, verb)
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(s, e.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+// panicHookHolder gives every value stored in [panicHook] the same concrete
+// type, as [atomic.Value] requires.
+type panicHookHolder struct {
+	fn func(err *PanicError)
+}
+
+// panicHook holds the function called with every [PanicError] produced by
+// this package. It is read from task goroutines, hence the atomic.
+var panicHook atomic.Value // panicHookHolder
+
+// loadPanicHook returns the registered panic hook, or nil.
+func loadPanicHook() func(err *PanicError) {
+	h, _ := panicHook.Load().(panicHookHolder)
+	return h.fn
+}
+
+// SetPanicHook registers fn to be called with every panic recovered from a
+// task, for example to report it to an error tracker. The hook runs in the
+// goroutine of the panicking task before the Future resolves. Passing nil
+// removes the hook.
+func SetPanicHook(fn func(err *PanicError)) {
+	panicHook.Store(panicHookHolder{fn: fn})
+}
@@ -0,0 +1,61 @@
+package future
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/saltfishpr/pkg/routine"
+)
+
+func TestPanicError(t *testing.T) {
+	var hooked *PanicError
+	SetPanicHook(func(err *PanicError) { hooked = err })
+	defer SetPanicHook(nil)
+
+	_, err := Async(func() (int, error) {
+		panic("boom")
+	}).Get()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrPanic)
+	assert.Equal(t, "async panic: boom", err.Error())
+
+	var perr *PanicError
+	require.True(t, errors.As(err, &perr))
+	assert.Equal(t, "boom", perr.Value)
+	assert.Same(t, perr, hooked)
+	require.NotEmpty(t, perr.StackTrace())
+	assert.Contains(t, fmt.Sprintf("%+v", err), "TestPanicError")
+
+	var rerr *routine.RecoveredError
+	require.True(t, errors.As(err, &rerr))
+	assert.Equal(t, "boom", rerr.Value)
+}
+
+func TestPanicError_ErrorValue(t *testing.T) {
+	cause := errors.New("cause")
+	_, err := Async(func() (int, error) {
+		panic(cause)
+	}).Get()
+	assert.ErrorIs(t, err, ErrPanic)
+	assert.ErrorIs(t, err, cause)
+}
+
+func TestSetPanicHook_Concurrent(t *testing.T) {
+	defer SetPanicHook(nil)
+
+	fs := make([]*Future[int], 0, 20)
+	for i := 0; i < 20; i++ {
+		fs = append(fs, Async(func() (int, error) {
+			panic("boom")
+		}))
+		SetPanicHook(func(*PanicError) {})
+	}
+	for _, f := range fs {
+		_, err := f.Get()
+		assert.ErrorIs(t, err, ErrPanic)
+	}
+}
@@ -0,0 +1,129 @@
+package future
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Delay runs f on the package-level executor after d and returns a [Future]
+// for its result. Delays share a single timer wheel with a 10 ms tick, so
+// thousands of pending delays stay cheap; the flip side is that d is rounded
+// up to the next tick. Panics inside f are surfaced as a [*PanicError].
+func Delay[T any](d time.Duration, f func() (T, error)) *Future[T] {
+	s := newState[T]()
+	if d <= 0 {
+		submit(loadExecutor(), s, f)
+	} else {
+		defaultWheel.add(d, func() {
+			submit(loadExecutor(), s, f)
+		})
+	}
+	return &Future[T]{state: s}
+}
+
+// Schedule is like [Delay] but runs f at the given time. A time in the past
+// runs f immediately.
+func Schedule[T any](at time.Time, f func() (T, error)) *Future[T] {
+	return Delay(time.Until(at), f)
+}
+
+// Periodic is a repeating task started by [Every].
+type Periodic[T any] struct {
+	interval time.Duration
+	f        func() (T, error)
+	running  atomic.Bool
+	ticks    atomic.Int64
+
+	mu        sync.Mutex
+	next      *state[T]
+	entry     *timerEntry
+	cancelled bool
+}
+
+// Every runs f on the package-level executor once per interval until the
+// returned [Periodic] is cancelled. Ticks run at a fixed rate on the shared
+// timer wheel used by [Delay]; a tick that comes due while the previous run
+// of f is still in progress is skipped rather than queued.
+//
+// It panics if interval is not positive.
+func Every[T any](interval time.Duration, f func() (T, error)) *Periodic[T] {
+	if interval <= 0 {
+		panic("future: interval must be positive")
+	}
+	p := &Periodic[T]{
+		interval: interval,
+		f:        f,
+		next:     newState[T](),
+	}
+	p.entry = defaultWheel.add(interval, p.fire)
+	return p
+}
+
+// Next returns a [Future] that resolves with the result of the next run of
+// f to complete, or with [ErrCancelled] if p is cancelled first.
+func (p *Periodic[T]) Next() *Future[T] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return &Future[T]{state: p.next}
+}
+
+// Ticks returns the number of completed runs of f.
+func (p *Periodic[T]) Ticks() int64 {
+	return p.ticks.Load()
+}
+
+// Cancel stops further ticks and resolves the Future returned by
+// [Periodic.Next] with [ErrCancelled]. A run already in progress finishes,
+// but its result is discarded. Calling Cancel more than once is a no-op.
+func (p *Periodic[T]) Cancel() {
+	p.mu.Lock()
+	if p.cancelled {
+		p.mu.Unlock()
+		return
+	}
+	p.cancelled = true
+	p.entry.cancel()
+	s := p.next
+	p.mu.Unlock()
+
+	var zero T
+	s.set(zero, ErrCancelled)
+}
+
+// fire is called by the timer wheel on every tick. It schedules the
+// following tick and starts f unless the previous run is still going.
+func (p *Periodic[T]) fire() {
+	p.mu.Lock()
+	if p.cancelled {
+		p.mu.Unlock()
+		return
+	}
+	p.entry = defaultWheel.add(p.interval, p.fire)
+	p.mu.Unlock()
+
+	if !p.running.CompareAndSwap(false, true) {
+		return
+	}
+	s := newState[T]()
+	submit(loadExecutor(), s, p.f)
+	s.subscribe(func(val T, err error) {
+		p.running.Store(false)
+		p.publish(val, err)
+	})
+}
+
+// publish resolves the current Next Future and replaces it with a fresh one.
+func (p *Periodic[T]) publish(val T, err error) {
+	p.mu.Lock()
+	if p.cancelled {
+		p.mu.Unlock()
+		return
+	}
+	s := p.next
+	p.next = newState[T]()
+	p.ticks.Add(1)
+	p.mu.Unlock()
+
+	s.set(val, err)
+}
@@ -0,0 +1,82 @@
+package future
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelay(t *testing.T) {
+	start := time.Now()
+	val, err := Delay(30*time.Millisecond, func() (int, error) {
+		return 1, nil
+	}).Get()
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+}
+
+func TestDelay_RunningWheel(t *testing.T) {
+	// Keep the wheel running and add entries between ticks.
+	keep := Delay(200*time.Millisecond, func() (int, error) { return 0, nil })
+	for i := 0; i < 5; i++ {
+		time.Sleep(7 * time.Millisecond)
+		start := time.Now()
+		_, err := Delay(20*time.Millisecond, func() (int, error) { return 0, nil }).Get()
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	}
+	_, err := keep.Get()
+	require.NoError(t, err)
+}
+
+func TestDelay_Many(t *testing.T) {
+	fs := make([]*Future[int], 1000)
+	for i := range fs {
+		i := i
+		fs[i] = Delay(time.Duration(i%50)*time.Millisecond, func() (int, error) {
+			return i, nil
+		})
+	}
+	vals, err := AllOf(fs...).Get()
+	require.NoError(t, err)
+	for i, v := range vals {
+		assert.Equal(t, i, v)
+	}
+}
+
+func TestSchedule_Past(t *testing.T) {
+	val, err := Schedule(time.Now().Add(-time.Second), func() (string, error) {
+		return "now", nil
+	}).GetTimeout(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "now", val)
+}
+
+func TestEvery(t *testing.T) {
+	var n int32
+	p := Every(10*time.Millisecond, func() (int32, error) {
+		return atomic.AddInt32(&n, 1), nil
+	})
+
+	first, err := p.Next().Get()
+	require.NoError(t, err)
+	second, err := p.Next().Get()
+	require.NoError(t, err)
+	assert.Greater(t, second, first)
+	assert.GreaterOrEqual(t, p.Ticks(), int64(2))
+
+	next := p.Next()
+	p.Cancel()
+	p.Cancel()
+	_, err = next.Get()
+	if err != nil {
+		assert.True(t, errors.Is(err, ErrCancelled))
+	}
+	_, err = p.Next().Get()
+	assert.ErrorIs(t, err, ErrCancelled)
+}
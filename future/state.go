@@ -1,8 +1,10 @@
 package future
 
 import (
+	"context"
 	"sync"
 	"sync/atomic"
+	"time"
 	"unsafe"
 )
 
@@ -27,6 +29,18 @@ type state[T any] struct {
 	err error
 
 	stack unsafe.Pointer // *callback[T]; lock-free Treiber stack
+
+	debug *debugRecord // creation site; nil unless debug mode was on
+}
+
+// newState creates an unresolved state, registering it for [Pending] when
+// debug mode is enabled.
+func newState[T any]() *state[T] {
+	s := &state[T]{}
+	if debugEnabled.Load() {
+		s.debug = trackState[T]()
+	}
+	return s
 }
 
 // lazyInit creates the done channel on first use, avoiding allocation for
@@ -46,6 +60,9 @@ func (s *state[T]) set(val T, err error) bool {
 	}
 	s.val = val
 	s.err = err
+	if s.debug != nil {
+		untrackState(s.debug)
+	}
 
 	s.state.CompareAndSwap(stateDoing, stateDone)
 	s.lazyInit()
@@ -75,6 +92,40 @@ func (s *state[T]) get() (T, error) {
 	return s.val, s.err
 }
 
+// getContext is like get but returns early with ctx.Err() when ctx is done
+// before the state is resolved.
+func (s *state[T]) getContext(ctx context.Context) (T, error) {
+	if s.isDone() {
+		return s.val, s.err
+	}
+	s.lazyInit()
+	select {
+	case <-s.done:
+		return s.val, s.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// getTimeout is like get but returns early with [ErrTimeout] when the state
+// is not resolved within d.
+func (s *state[T]) getTimeout(d time.Duration) (T, error) {
+	if s.isDone() {
+		return s.val, s.err
+	}
+	s.lazyInit()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-s.done:
+		return s.val, s.err
+	case <-timer.C:
+		var zero T
+		return zero, ErrTimeout
+	}
+}
+
 // subscribe pushes cb onto the lock-free stack. If the state is already
 // resolved, cb is invoked immediately in the caller's goroutine.
 func (s *state[T]) subscribe(cb func(T, error)) {
@@ -0,0 +1,281 @@
+package future
+
+import (
+	"context"
+	"sync"
+)
+
+// Result is a single output item of [Stream]. Index is the position of the
+// corresponding input item, starting at zero.
+type Result[T any] struct {
+	Index int
+	Val   T
+	Err   error
+}
+
+// streamOptions holds the resolved configuration for a [Stream] call.
+type streamOptions struct {
+	executor        Executor
+	unordered       bool
+	continueOnError bool
+	buffer          int
+}
+
+// StreamOption configures the behavior of [Stream], [StreamSlice] and
+// [MapSlice].
+type StreamOption func(*streamOptions)
+
+// WithStreamExecutor sets the executor that runs every item. The default
+// is the executor carried by the context (see [ContextExecutor]).
+func WithStreamExecutor(e Executor) StreamOption {
+	return func(opts *streamOptions) {
+		opts.executor = e
+	}
+}
+
+// WithUnordered emits results as soon as they complete instead of in input
+// order. Ordered output, the default, holds back finished items behind a
+// slow one, which bounds throughput by the slowest in-flight item.
+func WithUnordered() StreamOption {
+	return func(opts *streamOptions) {
+		opts.unordered = true
+	}
+}
+
+// WithContinueOnError keeps processing after an item fails; failed items
+// are emitted with their error. By default the stream stops at the first
+// failure: the error is emitted, in-flight items are cancelled through their
+// context and the output channel is closed.
+func WithContinueOnError() StreamOption {
+	return func(opts *streamOptions) {
+		opts.continueOnError = true
+	}
+}
+
+// WithStreamBuffer sets the capacity of the output channel. The default is
+// 0, so a slow consumer immediately applies back-pressure.
+func WithStreamBuffer(n int) StreamOption {
+	return func(opts *streamOptions) {
+		opts.buffer = n
+	}
+}
+
+// Stream applies fn to every item received from in, running at most
+// concurrency items at a time, and delivers the results on the returned
+// channel. Panics inside fn are surfaced as errors wrapping [ErrPanic].
+//
+// The stream reads from in only while a slot is free and the consumer keeps
+// up, so a slow consumer slows down the producer. The output channel is
+// closed once in is closed and every result has been delivered, when the
+// stream stops on an error, or when ctx is done. A consumer that stops
+// reading early must cancel ctx to release the stream's goroutines.
+//
+// It panics if concurrency is not positive.
+func Stream[T any, R any](
+	ctx context.Context, in <-chan T, concurrency int,
+	fn func(ctx context.Context, item T) (R, error), options ...StreamOption,
+) <-chan Result[R] {
+	return newStream(ctx, concurrency, fn, options, func(ctx context.Context) (item T, ok bool) {
+		select {
+		case <-ctx.Done():
+			return item, false
+		case item, ok = <-in:
+			return item, ok
+		}
+	})
+}
+
+// StreamSlice is like [Stream] but reads its input from items.
+func StreamSlice[T any, R any](
+	ctx context.Context, items []T, concurrency int,
+	fn func(ctx context.Context, item T) (R, error), options ...StreamOption,
+) <-chan Result[R] {
+	i := 0
+	return newStream(ctx, concurrency, fn, options, func(context.Context) (item T, ok bool) {
+		if i >= len(items) {
+			return item, false
+		}
+		item = items[i]
+		i++
+		return item, true
+	})
+}
+
+// MapSlice applies fn to every element of items with bounded concurrency,
+// like [StreamSlice], and collects the values in input order. If any item
+// fails, the returned Future resolves with the error of the failed item with
+// the lowest index together with the values collected so far. If ctx is
+// done first, it resolves with ctx.Err().
+func MapSlice[T any, R any](
+	ctx context.Context, items []T, concurrency int,
+	fn func(ctx context.Context, item T) (R, error), options ...StreamOption,
+) *Future[[]R] {
+	if len(items) == 0 {
+		return Done[[]R](nil)
+	}
+
+	ch := StreamSlice(ctx, items, concurrency, fn, options...)
+	s := newState[[]R]()
+	go func() {
+		vals := make([]R, len(items))
+		var firstErr error
+		firstIdx := len(items)
+		for r := range ch {
+			if r.Err != nil {
+				if r.Index < firstIdx {
+					firstErr, firstIdx = r.Err, r.Index
+				}
+				continue
+			}
+			vals[r.Index] = r.Val
+		}
+		if firstErr == nil {
+			firstErr = ctx.Err()
+		}
+		s.set(vals, firstErr)
+	}()
+	return &Future[[]R]{state: s}
+}
+
+// stream runs a single [Stream] call. A dispatcher goroutine pulls items
+// and submits them to the executor, and an emitter goroutine forwards their
+// results to out.
+type stream[T any, R any] struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	opts   streamOptions
+	fn     func(ctx context.Context, item T) (R, error)
+	next   func(ctx context.Context) (T, bool)
+
+	sem     chan struct{}   // limits the number of items in flight
+	queue   chan indexed[R] // ordered mode: futures in input order
+	results chan Result[R]  // unordered mode: completed results, as large as sem
+	wg      sync.WaitGroup  // unordered mode: tasks yet to report
+	out     chan Result[R]
+}
+
+// indexed pairs an in-flight item's Future with its input position.
+type indexed[R any] struct {
+	index  int
+	future *Future[R]
+}
+
+func newStream[T any, R any](
+	ctx context.Context, concurrency int,
+	fn func(ctx context.Context, item T) (R, error), options []StreamOption,
+	next func(ctx context.Context) (T, bool),
+) <-chan Result[R] {
+	if concurrency <= 0 {
+		panic("future: concurrency must be positive")
+	}
+	opts := streamOptions{
+		executor: ContextExecutor(ctx),
+	}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	st := &stream[T, R]{
+		ctx:    ctx,
+		cancel: cancel,
+		opts:   opts,
+		fn:     fn,
+		next:   next,
+		sem:    make(chan struct{}, concurrency),
+		out:    make(chan Result[R], opts.buffer),
+	}
+	if opts.unordered {
+		st.results = make(chan Result[R], concurrency)
+	} else {
+		st.queue = make(chan indexed[R], concurrency)
+	}
+	go st.dispatch()
+	go st.emit()
+	return st.out
+}
+
+func (st *stream[T, R]) dispatch() {
+	if st.opts.unordered {
+		defer func() {
+			st.wg.Wait()
+			close(st.results)
+		}()
+	} else {
+		defer close(st.queue)
+	}
+
+	for index := 0; ; index++ {
+		item, ok := st.next(st.ctx)
+		if !ok {
+			return
+		}
+		select {
+		case <-st.ctx.Done():
+			return
+		case st.sem <- struct{}{}:
+		}
+
+		f := SubmitContext(st.ctx, st.opts.executor, func() (R, error) {
+			return st.fn(st.ctx, item)
+		})
+		if st.opts.unordered {
+			index := index
+			st.wg.Add(1)
+			// The item keeps its slot in sem until the emitter takes its
+			// result, so results always has room and the callback, which
+			// runs on the executor, never blocks.
+			f.Subscribe(func(val R, err error) {
+				st.results <- Result[R]{Index: index, Val: val, Err: err}
+				st.wg.Done()
+			})
+			continue
+		}
+		f.Subscribe(func(R, error) { <-st.sem })
+		select {
+		case st.queue <- indexed[R]{index: index, future: f}:
+		case <-st.ctx.Done():
+			return
+		}
+	}
+}
+
+func (st *stream[T, R]) emit() {
+	defer close(st.out)
+	defer st.cancel()
+
+	for {
+		var r Result[R]
+		if st.opts.unordered {
+			var ok bool
+			select {
+			case r, ok = <-st.results:
+				if !ok {
+					return
+				}
+				<-st.sem
+			case <-st.ctx.Done():
+				return
+			}
+		} else {
+			p, ok := <-st.queue
+			if !ok {
+				return
+			}
+			val, err := p.future.GetContext(st.ctx)
+			if st.ctx.Err() != nil {
+				return
+			}
+			r = Result[R]{Index: p.index, Val: val, Err: err}
+		}
+
+		select {
+		case st.out <- r:
+		case <-st.ctx.Done():
+			return
+		}
+		if r.Err != nil && !st.opts.continueOnError {
+			return
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package future
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/saltfishpr/pkg/future/executors"
+)
+
+func TestStream_Ordered(t *testing.T) {
+	in := make(chan int)
+	go func() {
+		defer close(in)
+		for i := 0; i < 10; i++ {
+			in <- i
+		}
+	}()
+
+	var got []int
+	for r := range Stream(context.Background(), in, 4, func(ctx context.Context, v int) (int, error) {
+		time.Sleep(time.Duration(10-v) * time.Millisecond)
+		return v * v, nil
+	}) {
+		require.NoError(t, r.Err)
+		assert.Equal(t, len(got), r.Index)
+		got = append(got, r.Val)
+	}
+	assert.Equal(t, []int{0, 1, 4, 9, 16, 25, 36, 49, 64, 81}, got)
+}
+
+func TestStreamSlice_Unordered(t *testing.T) {
+	var inflight, peak int32
+	var got []int
+	for r := range StreamSlice(context.Background(), []int{1, 2, 3, 4, 5, 6}, 2, func(ctx context.Context, v int) (int, error) {
+		n := atomic.AddInt32(&inflight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&inflight, -1)
+		return v, nil
+	}, WithUnordered()) {
+		require.NoError(t, r.Err)
+		got = append(got, r.Val)
+	}
+	sort.Ints(got)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, got)
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+}
+
+func TestStreamSlice_SharedPool(t *testing.T) {
+	pool := executors.NewWorkerPool(2)
+	defer pool.Shutdown(context.Background())
+	ctx := WithExecutorContext(context.Background(), pool)
+
+	for _, opts := range [][]StreamOption{nil, {WithUnordered()}} {
+		items := []int{1, 2, 3, 4, 5, 6, 7, 8}
+		ch := StreamSlice(ctx, items, 2, func(ctx context.Context, v int) (int, error) {
+			return v, nil
+		}, opts...)
+
+		// The consumer runs its own work on the pool the stream uses.
+		sum := 0
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for r := range ch {
+				time.Sleep(time.Millisecond)
+				v, err := AsyncContext(ctx, func() (int, error) { return r.Val * 2, nil }).Get()
+				assert.NoError(t, err)
+				sum += v
+			}
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("stream deadlocked on the shared pool")
+		}
+		assert.Equal(t, 72, sum)
+	}
+}
+
+func TestStreamSlice_StopOnError(t *testing.T) {
+	wantErr := errors.New("boom")
+	var results []Result[int]
+	for r := range StreamSlice(context.Background(), []int{0, 1, 2, 3, 4}, 1, func(ctx context.Context, v int) (int, error) {
+		if v == 2 {
+			return 0, wantErr
+		}
+		return v, nil
+	}) {
+		results = append(results, r)
+	}
+	require.Len(t, results, 3)
+	assert.ErrorIs(t, results[2].Err, wantErr)
+}
+
+func TestStreamSlice_ContinueOnError(t *testing.T) {
+	wantErr := errors.New("boom")
+	var errs int
+	var n int
+	for r := range StreamSlice(context.Background(), []int{0, 1, 2, 3, 4}, 2, func(ctx context.Context, v int) (int, error) {
+		if v%2 == 1 {
+			return 0, wantErr
+		}
+		return v, nil
+	}, WithContinueOnError()) {
+		n++
+		if r.Err != nil {
+			errs++
+		}
+	}
+	assert.Equal(t, 5, n)
+	assert.Equal(t, 2, errs)
+}
+
+func TestMapSlice(t *testing.T) {
+	vals, err := MapSlice(context.Background(), []string{"a", "bb", "ccc"}, 2, func(ctx context.Context, s string) (int, error) {
+		return len(s), nil
+	}).Get()
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, vals)
+
+	_, err = MapSlice(context.Background(), []int{1}, 1, func(ctx context.Context, v int) (int, error) {
+		panic("boom")
+	}).Get()
+	assert.ErrorIs(t, err, ErrPanic)
+}
+
+func TestMapSlice_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := MapSlice(ctx, []int{1, 2, 3}, 1, func(ctx context.Context, v int) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	cancel()
+	_, err := f.Get()
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,126 @@
+package future
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Default geometry of the shared timer wheel used by [Delay], [Schedule]
+// and [Every]: a 10 ms tick over 512 slots covers about 5 s per revolution;
+// longer delays wait for extra revolutions.
+const (
+	wheelTick  = 10 * time.Millisecond
+	wheelSlots = 512
+)
+
+var defaultWheel = newTimerWheel(wheelTick, wheelSlots)
+
+// timerWheel is a hashed timing wheel. Each slot holds the entries that
+// expire when the cursor reaches it, with rounds counting the remaining
+// revolutions for delays longer than a full turn.
+//
+// A single goroutine advances the cursor, and only while entries are
+// pending, so an idle wheel costs nothing. Expiry is rounded up to the next
+// tick: a delay added between ticks also counts the time already elapsed
+// since the last one, so entries never fire early.
+type timerWheel struct {
+	tick time.Duration
+
+	mu      sync.Mutex
+	slots   [][]*timerEntry
+	pos     int
+	count   int
+	running bool
+	last    time.Time // when the cursor last moved, while running
+}
+
+// timerEntry is a callback registered on a [timerWheel].
+type timerEntry struct {
+	rounds    int
+	fn        func()
+	cancelled atomic.Bool
+}
+
+// cancel prevents fn from running if it has not fired yet.
+func (e *timerEntry) cancel() {
+	e.cancelled.Store(true)
+}
+
+func newTimerWheel(tick time.Duration, slots int) *timerWheel {
+	return &timerWheel{
+		tick:  tick,
+		slots: make([][]*timerEntry, slots),
+	}
+}
+
+// add registers fn to run on the wheel goroutine after d. fn must not
+// block; it typically hands work to an [Executor].
+func (w *timerWheel) add(d time.Duration, fn func()) *timerEntry {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.running {
+		// The next tick comes less than a full tick from now.
+		d += time.Since(w.last)
+	}
+	ticks := int((d + w.tick - 1) / w.tick)
+	if ticks < 1 {
+		ticks = 1
+	}
+
+	n := len(w.slots)
+	e := &timerEntry{rounds: (ticks - 1) / n, fn: fn}
+	slot := (w.pos + ticks) % n
+	w.slots[slot] = append(w.slots[slot], e)
+	w.count++
+	if !w.running {
+		w.running = true
+		w.last = time.Now()
+		go w.run()
+	}
+	return e
+}
+
+// run advances the cursor once per tick and fires due entries. It exits
+// when no entries remain.
+func (w *timerWheel) run() {
+	ticker := time.NewTicker(w.tick)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		w.mu.Lock()
+		w.pos = (w.pos + 1) % len(w.slots)
+		w.last = now
+		var due []*timerEntry
+		entries := w.slots[w.pos]
+		kept := entries[:0]
+		for _, e := range entries {
+			if e.rounds > 0 {
+				e.rounds--
+				kept = append(kept, e)
+				continue
+			}
+			due = append(due, e)
+		}
+		for i := len(kept); i < len(entries); i++ {
+			entries[i] = nil
+		}
+		w.slots[w.pos] = kept
+		w.count -= len(due)
+		idle := w.count == 0
+		if idle {
+			w.running = false
+		}
+		w.mu.Unlock()
+
+		for _, e := range due {
+			if !e.cancelled.Load() {
+				e.fn()
+			}
+		}
+		if idle {
+			return
+		}
+	}
+}
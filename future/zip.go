@@ -0,0 +1,135 @@
+package future
+
+import "sync/atomic"
+
+// Tuple2 holds the results of two Futures combined by [Zip2].
+type Tuple2[T1, T2 any] struct {
+	V1 T1
+	V2 T2
+}
+
+// Tuple3 holds the results of three Futures combined by [Zip3].
+type Tuple3[T1, T2, T3 any] struct {
+	V1 T1
+	V2 T2
+	V3 T3
+}
+
+// Tuple4 holds the results of four Futures combined by [Zip4].
+type Tuple4[T1, T2, T3, T4 any] struct {
+	V1 T1
+	V2 T2
+	V3 T3
+	V4 T4
+}
+
+// Tuple5 holds the results of five Futures combined by [Zip5].
+type Tuple5[T1, T2, T3, T4, T5 any] struct {
+	V1 T1
+	V2 T2
+	V3 T3
+	V4 T4
+	V5 T5
+}
+
+// Zip2 waits for f1 and f2 and combines their values into a [Tuple2].
+// Unlike [AllOf], the Futures may carry different types. If any Future
+// fails, the returned Future resolves immediately with that error.
+func Zip2[T1, T2 any](f1 *Future[T1], f2 *Future[T2]) *Future[Tuple2[T1, T2]] {
+	s := newState[Tuple2[T1, T2]]()
+	var t Tuple2[T1, T2]
+	j := newJoiner(2, func(err error) {
+		if err != nil {
+			s.set(Tuple2[T1, T2]{}, err)
+			return
+		}
+		s.set(t, nil)
+	})
+	f1.state.subscribe(func(val T1, err error) { t.V1 = val; j.done(err) })
+	f2.state.subscribe(func(val T2, err error) { t.V2 = val; j.done(err) })
+	return &Future[Tuple2[T1, T2]]{state: s}
+}
+
+// Zip3 is like [Zip2] for three Futures.
+func Zip3[T1, T2, T3 any](f1 *Future[T1], f2 *Future[T2], f3 *Future[T3]) *Future[Tuple3[T1, T2, T3]] {
+	s := newState[Tuple3[T1, T2, T3]]()
+	var t Tuple3[T1, T2, T3]
+	j := newJoiner(3, func(err error) {
+		if err != nil {
+			s.set(Tuple3[T1, T2, T3]{}, err)
+			return
+		}
+		s.set(t, nil)
+	})
+	f1.state.subscribe(func(val T1, err error) { t.V1 = val; j.done(err) })
+	f2.state.subscribe(func(val T2, err error) { t.V2 = val; j.done(err) })
+	f3.state.subscribe(func(val T3, err error) { t.V3 = val; j.done(err) })
+	return &Future[Tuple3[T1, T2, T3]]{state: s}
+}
+
+// Zip4 is like [Zip2] for four Futures.
+func Zip4[T1, T2, T3, T4 any](
+	f1 *Future[T1], f2 *Future[T2], f3 *Future[T3], f4 *Future[T4],
+) *Future[Tuple4[T1, T2, T3, T4]] {
+	s := newState[Tuple4[T1, T2, T3, T4]]()
+	var t Tuple4[T1, T2, T3, T4]
+	j := newJoiner(4, func(err error) {
+		if err != nil {
+			s.set(Tuple4[T1, T2, T3, T4]{}, err)
+			return
+		}
+		s.set(t, nil)
+	})
+	f1.state.subscribe(func(val T1, err error) { t.V1 = val; j.done(err) })
+	f2.state.subscribe(func(val T2, err error) { t.V2 = val; j.done(err) })
+	f3.state.subscribe(func(val T3, err error) { t.V3 = val; j.done(err) })
+	f4.state.subscribe(func(val T4, err error) { t.V4 = val; j.done(err) })
+	return &Future[Tuple4[T1, T2, T3, T4]]{state: s}
+}
+
+// Zip5 is like [Zip2] for five Futures.
+func Zip5[T1, T2, T3, T4, T5 any](
+	f1 *Future[T1], f2 *Future[T2], f3 *Future[T3], f4 *Future[T4], f5 *Future[T5],
+) *Future[Tuple5[T1, T2, T3, T4, T5]] {
+	s := newState[Tuple5[T1, T2, T3, T4, T5]]()
+	var t Tuple5[T1, T2, T3, T4, T5]
+	j := newJoiner(5, func(err error) {
+		if err != nil {
+			s.set(Tuple5[T1, T2, T3, T4, T5]{}, err)
+			return
+		}
+		s.set(t, nil)
+	})
+	f1.state.subscribe(func(val T1, err error) { t.V1 = val; j.done(err) })
+	f2.state.subscribe(func(val T2, err error) { t.V2 = val; j.done(err) })
+	f3.state.subscribe(func(val T3, err error) { t.V3 = val; j.done(err) })
+	f4.state.subscribe(func(val T4, err error) { t.V4 = val; j.done(err) })
+	f5.state.subscribe(func(val T5, err error) { t.V5 = val; j.done(err) })
+	return &Future[Tuple5[T1, T2, T3, T4, T5]]{state: s}
+}
+
+// joiner counts down completions of a fixed number of Futures and calls
+// finish exactly once: with the first error, or with nil after all succeed.
+type joiner struct {
+	failed  uint32
+	pending int32
+	finish  func(err error)
+}
+
+func newJoiner(n int, finish func(err error)) *joiner {
+	return &joiner{pending: int32(n), finish: finish}
+}
+
+// done records the completion of one Future. Each Future's value must be
+// stored before calling done so that finish observes it.
+func (j *joiner) done(err error) {
+	if err != nil {
+		if atomic.CompareAndSwapUint32(&j.failed, 0, 1) {
+			j.finish(err)
+		}
+		return
+	}
+	if atomic.AddInt32(&j.pending, -1) == 0 {
+		j.finish(nil)
+	}
+}